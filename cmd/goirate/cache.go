@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"gitlab.com/haath/goirate/pkg/movies"
+	"gitlab.com/haath/goirate/pkg/series"
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+// CacheConfig holds the time for which the responses of the TVDB, the OMDb and IMDb are cached.
+type CacheConfig struct {
+	Disabled       bool   `toml:"disabled"`
+	SeriesSearches string `toml:"series_searches"`
+	Series         string `toml:"series"`
+	Episodes       string `toml:"episodes"`
+	MovieSearches  string `toml:"movie_searches"`
+	Movies         string `toml:"movies"`
+}
+
+// CacheCommand defines the cache command and its subcommands.
+type CacheCommand struct {
+	Clear cacheClearCommand `command:"clear" description:"Delete all cached responses."`
+	Stats cacheStatsCommand `command:"stats" description:"Print out statistics of the cached responses."`
+}
+
+type cacheClearCommand struct{}
+type cacheStatsCommand struct{}
+
+// Execute is the callback of the cache clear command.
+func (cmd *cacheClearCommand) Execute(args []string) error {
+
+	if err := utils.NewResponseCache(cachePath()).Clear(); err != nil {
+		return err
+	}
+
+	log.Printf("Cleared the cache at %v\n", cachePath())
+
+	return nil
+}
+
+// Execute is the callback of the cache stats command.
+func (cmd *cacheStatsCommand) Execute(args []string) error {
+
+	stats, err := utils.NewResponseCache(cachePath()).Stats()
+
+	if err != nil {
+		return err
+	}
+
+	if Options.JSON {
+
+		statsJSON, err := json.MarshalIndent(stats, "", "   ")
+
+		if err != nil {
+			return err
+		}
+
+		log.Println(string(statsJSON))
+
+	} else {
+
+		log.Print(getCacheStatsTable(stats))
+	}
+
+	return nil
+}
+
+func getCacheStatsTable(stats utils.CacheStats) string {
+	buf := bytes.NewBufferString("")
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02 15:04")
+	}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	table.SetAutoFormatHeaders(false)
+
+	table.Append([]string{"Responses", fmt.Sprint(stats.Entries)})
+	table.Append([]string{"Expired", fmt.Sprint(stats.Expired)})
+	table.Append([]string{"Size", fmt.Sprintf("%.1f KB", float64(stats.Size)/1024)})
+	table.Append([]string{"Oldest", formatTime(stats.Oldest)})
+	table.Append([]string{"Newest", formatTime(stats.Newest)})
+
+	table.Render()
+
+	return buf.String()
+}
+
+// applyCacheConfig sets the cache TTLs of the metadata APIs from the configuration, and enables the cache unless it is disabled.
+func applyCacheConfig(config CacheConfig) {
+
+	setTTL := func(ttl *time.Duration, value string) {
+		if duration, err := utils.ParseDuration(value); err == nil {
+			*ttl = duration
+		} else if value != "" {
+			log.Printf("invalid cache duration %v: %v\n", value, err)
+		}
+	}
+
+	setTTL(&series.SearchCacheTTL, config.SeriesSearches)
+	setTTL(&series.SeriesCacheTTL, config.Series)
+	setTTL(&series.EpisodesCacheTTL, config.Episodes)
+	setTTL(&movies.SearchCacheTTL, config.MovieSearches)
+	setTTL(&movies.MovieCacheTTL, config.Movies)
+
+	if config.Disabled {
+		utils.SetCache(nil)
+	} else {
+		utils.SetCache(utils.NewResponseCache(cachePath()))
+	}
+}
+
+func cachePath() string {
+
+	return path.Join(configDir(), "cache")
+}
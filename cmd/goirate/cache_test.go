@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/movies"
+	"gitlab.com/haath/goirate/pkg/series"
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+func TestGetCacheStatsTable(t *testing.T) {
+
+	stats := utils.CacheStats{
+		Entries: 12,
+		Expired: 3,
+		Size:    2048,
+		Oldest:  time.Date(2020, 5, 1, 12, 0, 0, 0, time.Local),
+	}
+
+	table := getCacheStatsTable(stats)
+
+	for _, want := range []string{"12", "3", "2.0 KB", "2020-05-01 12:00"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("missing %v in:\n%v", want, table)
+		}
+	}
+}
+
+func TestApplyCacheConfig(t *testing.T) {
+
+	defaultTTL := series.EpisodesCacheTTL
+	defaultMovieTTL := movies.MovieCacheTTL
+	defer func() {
+		series.EpisodesCacheTTL = defaultTTL
+		movies.MovieCacheTTL = defaultMovieTTL
+		utils.SetCache(nil)
+	}()
+
+	applyCacheConfig(CacheConfig{Episodes: "90m", Series: "invalid", Movies: "7d"})
+
+	if series.EpisodesCacheTTL != 90*time.Minute {
+		t.Errorf("got %v, want %v", series.EpisodesCacheTTL, 90*time.Minute)
+	}
+
+	if movies.MovieCacheTTL != 7*24*time.Hour {
+		t.Errorf("got %v, want %v", movies.MovieCacheTTL, 7*24*time.Hour)
+	}
+
+	if utils.GetCache() == nil {
+		t.Errorf("expected the cache to be enabled")
+	}
+
+	applyCacheConfig(CacheConfig{Disabled: true})
+
+	if utils.GetCache() != nil {
+		t.Errorf("expected the cache to be disabled")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"gitlab.com/haath/goirate/pkg/movies"
@@ -21,10 +22,17 @@ var Config struct {
 	torrents.SearchFilters
 	KodiMediaPaths    bool                   `toml:"kodi_media_paths"`
 	TPBMirrors        torrents.MirrorFilters `toml:"tpb_mirrors"`
+	TorrentSources    torrents.SourceConfig  `toml:"sources"`
+	Scoring           torrents.ScoringConfig `toml:"scoring"`
+	Trackers          []string               `toml:"trackers"`
 	TVDBCredentials   series.TVDBCredentials `toml:"tvdb"`
 	OMDBCredentials   movies.OMDBCredentials `toml:"omdb"`
 	QBittorrentConfig QBittorrentConfig      `toml:"qbittorrent"`
 	SMTPConfig        SMTPConfig             `toml:"smtp"`
+	Proxy             utils.ProxySettings    `toml:"proxy"`
+	Retries           utils.RetryConfig      `toml:"retries"`
+	RateLimit         utils.RateLimitConfig  `toml:"rate_limit"`
+	Cache             CacheConfig            `toml:"cache"`
 	Watchlist         utils.WatchlistActions `toml:"actions"`
 	DownloadDir       struct {
 		General string `toml:"general"`
@@ -130,6 +138,17 @@ func ImportConfig() {
 				*val = defaultVal
 			}
 		}
+		setOrDefaultFloat := func(val *float64, env string, defaultVal float64) {
+			if os.Getenv(env) != "" {
+				num, err := strconv.ParseFloat(os.Getenv(env), 64)
+				if err != nil {
+					log.Fatal(err)
+				}
+				*val = num
+			} else if *val == 0 {
+				*val = defaultVal
+			}
+		}
 		setOptionalBool := func(val *utils.OptionalBoolean, env string, defaultVal utils.OptionalBoolean) {
 			if os.Getenv(env) == "true" {
 				*val = utils.True
@@ -152,6 +171,12 @@ func ImportConfig() {
 		setOrDefault(&Config.MinSize, "GOIRATE_MIN_SIZE", "")
 		setOrDefault(&Config.MaxSize, "GOIRATE_MAX_SIZE", "")
 		setOrDefaultInt(&Config.MinSeeders, "GOIRATE_MIN_SEEDERS", 0)
+		if os.Getenv("GOIRATE_MIN_RELEASE") != "" {
+			Config.MinRelease = torrents.VideoRelease(os.Getenv("GOIRATE_MIN_RELEASE"))
+		}
+		if Config.ReleaseTypes.Whitelist == nil {
+			Config.ReleaseTypes.Whitelist = []torrents.VideoRelease{}
+		}
 
 		/*
 			Download directory options
@@ -183,6 +208,38 @@ func ImportConfig() {
 		setOrDefault(&Config.SMTPConfig.Username, "GOIRATE_SMTP_USERNAME", "")
 		setOrDefault(&Config.SMTPConfig.Password, "GOIRATE_SMTP_PASSWORD", "")
 
+		/*
+			Proxies
+		*/
+		setOrDefault(&Config.Proxy.URL, "GOIRATE_PROXY", "")
+		setOrDefault(&Config.Proxy.Torrents.URL, "GOIRATE_PROXY_TORRENTS", "")
+		setOrDefault(&Config.Proxy.Metadata.URL, "GOIRATE_PROXY_METADATA", "")
+		setOrDefault(&Config.Proxy.Download.URL, "GOIRATE_PROXY_DOWNLOAD", "")
+
+		/*
+			Retries and rate limiting
+		*/
+		setOrDefaultInt(&Config.Retries.MaxRetries, "GOIRATE_MAX_RETRIES", utils.DefaultMaxRetries)
+		setOrDefault(&Config.Retries.Backoff, "GOIRATE_RETRY_BACKOFF", utils.DefaultRetryBackoff.String())
+		setOrDefault(&Config.Retries.MaxBackoff, "GOIRATE_RETRY_MAX_BACKOFF", utils.DefaultMaxRetryBackoff.String())
+		setOrDefaultFloat(&Config.RateLimit.RequestsPerSecond, "GOIRATE_RATE_LIMIT", utils.DefaultRequestsPerSecond)
+		setOrDefaultInt(&Config.RateLimit.Burst, "GOIRATE_RATE_BURST", utils.DefaultRateBurst)
+
+		/*
+			Cache of the metadata APIs
+		*/
+		setBool(&Config.Cache.Disabled, "GOIRATE_CACHE_DISABLED")
+		setDefaultTTL := func(val *string, defaultVal time.Duration) {
+			if *val == "" {
+				*val = defaultVal.String()
+			}
+		}
+		setDefaultTTL(&Config.Cache.SeriesSearches, series.SearchCacheTTL)
+		setDefaultTTL(&Config.Cache.Series, series.SeriesCacheTTL)
+		setDefaultTTL(&Config.Cache.Episodes, series.EpisodesCacheTTL)
+		setDefaultTTL(&Config.Cache.MovieSearches, movies.SearchCacheTTL)
+		setDefaultTTL(&Config.Cache.Movies, movies.MovieCacheTTL)
+
 		/*
 			Watchlist options
 		*/
@@ -206,6 +263,55 @@ func ImportConfig() {
 		if Config.TPBMirrors.Blacklist == nil {
 			Config.TPBMirrors.Blacklist = []string{}
 		}
+		if Config.TPBMirrors.Static == nil {
+			Config.TPBMirrors.Static = []string{}
+		}
+
+		/*
+			Torrent sources
+		*/
+		if os.Getenv("GOIRATE_SOURCES") != "" {
+
+			Config.TorrentSources.Enabled = strings.Split(os.Getenv("GOIRATE_SOURCES"), ",")
+
+		} else if Config.TorrentSources.Enabled == nil {
+
+			Config.TorrentSources.Enabled = []string{torrents.DefaultSource}
+		}
+
+		/*
+			Torrent scoring
+		*/
+		if Config.Scoring.Weights == (torrents.ScoreWeights{}) {
+			Config.Scoring.Weights = torrents.DefaultScoreWeights
+		}
+		if Config.Scoring.PreferredCodecs == nil {
+			Config.Scoring.PreferredCodecs = []string{}
+		}
+		if Config.Scoring.PreferredGroups == nil {
+			Config.Scoring.PreferredGroups = []string{}
+		}
+		if Config.Scoring.SizePerMinute == nil {
+
+			Config.Scoring.SizePerMinute = map[string]float64{}
+
+			for quality, size := range torrents.DefaultSizePerMinute {
+				Config.Scoring.SizePerMinute[quality] = size
+			}
+		}
+
+		/*
+			Trackers added to magnet links
+		*/
+		if os.Getenv("GOIRATE_TRACKERS") != "" {
+
+			Config.Trackers = strings.Split(os.Getenv("GOIRATE_TRACKERS"), ",")
+
+		} else if Config.Trackers == nil {
+
+			Config.Trackers = append([]string{}, torrents.DefaultTrackers...)
+		}
+		torrents.DefaultTrackers = Config.Trackers
 
 		/*
 			Credentials
@@ -221,6 +327,16 @@ func ImportConfig() {
 		setBool(&Config.KodiMediaPaths, "GOIRATE_KODI_MEDIA_PATHS")
 	}
 
+	if err := utils.SetProxies(Config.Proxy); err != nil {
+		log.Fatal(err)
+	}
+	if err := Config.TPBMirrors.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	utils.SetRetries(Config.Retries)
+	utils.SetRateLimit(Config.RateLimit)
+	applyCacheConfig(Config.Cache)
+
 	ExportConfig()
 }
 
@@ -254,7 +370,22 @@ func applyFilters(dst *torrents.SearchFilters, src *torrents.SearchFilters) {
 	if src.MaxSize != "" {
 		dst.MaxSize = src.MaxSize
 	}
+	if src.MinSizePerMinute > 0 {
+		dst.MinSizePerMinute = src.MinSizePerMinute
+	}
+	if src.MaxSizePerMinute > 0 {
+		dst.MaxSizePerMinute = src.MaxSizePerMinute
+	}
 	dst.MinSeeders = src.MinSeeders
+	if src.MinRelease != "" {
+		dst.MinRelease = src.MinRelease
+	}
+	if src.MinAge != "" {
+		dst.MinAge = src.MinAge
+	}
+	if src.MaxAge != "" {
+		dst.MaxAge = src.MaxAge
+	}
 
 	for _, name := range src.Uploaders.Whitelist {
 		dst.Uploaders.Whitelist = append(dst.Uploaders.Whitelist, name)
@@ -262,6 +393,18 @@ func applyFilters(dst *torrents.SearchFilters, src *torrents.SearchFilters) {
 	for _, name := range src.Uploaders.Blacklist {
 		dst.Uploaders.Blacklist = append(dst.Uploaders.Blacklist, name)
 	}
+	for _, release := range src.ReleaseTypes.Whitelist {
+		dst.ReleaseTypes.Whitelist = append(dst.ReleaseTypes.Whitelist, release)
+	}
+	if src.ReleaseTypes.Blacklist != nil && dst.ReleaseTypes.Blacklist == nil {
+		// An empty blacklist disables the default one of video searches, so it is kept as well.
+		dst.ReleaseTypes.Blacklist = []torrents.VideoRelease{}
+	}
+	for _, release := range src.ReleaseTypes.Blacklist {
+		dst.ReleaseTypes.Blacklist = append(dst.ReleaseTypes.Blacklist, release)
+	}
+	dst.Release = dst.Release.Merge(src.Release)
+	dst.Filter = torrents.CombineFilterExpressions(dst.Filter, src.Filter)
 	dst.MirrorURL = src.MirrorURL
 	dst.ProxyListURL = src.ProxyListURL
 	dst.MirrorFilters = src.MirrorFilters
@@ -276,5 +419,19 @@ func configPath() string {
 // configuration passed to it from the Config variable.
 func GetMirrorScraper() *torrents.MirrorScraper {
 
-	return torrents.NewMirrorScraper(Config.ProxyListURL, Config.TPBMirrors)
+	scraper := torrents.NewMirrorScraper(Config.ProxyListURL, Config.TPBMirrors)
+	scraper.SetStatsPath(mirrorStatsPath())
+	scraper.SetCachePath(proxyListCachePath())
+
+	return scraper
+}
+
+func mirrorStatsPath() string {
+
+	return path.Join(configDir(), "mirrors.json")
+}
+
+func proxyListCachePath() string {
+
+	return path.Join(configDir(), "proxies.json")
 }
@@ -31,6 +31,59 @@ func TestImportExpor(t *testing.T) {
 	resetConfigs()
 }
 
+func TestImportTrackers(t *testing.T) {
+
+	resetConfigs()
+
+	defaultTrackers := torrents.DefaultTrackers
+
+	trackers := []string{"udp://tracker.opentrackr.org:1337/announce"}
+
+	Config.Trackers = trackers
+
+	ExportConfig()
+
+	Config.Trackers = nil
+
+	ImportConfig()
+
+	if !reflect.DeepEqual(torrents.DefaultTrackers, trackers) {
+		t.Errorf("\ngot %v\nwant %v", torrents.DefaultTrackers, trackers)
+	}
+
+	torrents.DefaultTrackers = defaultTrackers
+
+	resetConfigs()
+}
+
+func TestImportReleaseTypes(t *testing.T) {
+
+	resetConfigs()
+
+	Config.ReleaseTypes.Blacklist = nil
+
+	ExportConfig()
+
+	ImportConfig()
+
+	// The default blacklist is applied by the video searches, rather than to every search through the config.
+	if Config.ReleaseTypes.Blacklist != nil {
+		t.Errorf("got %v, want no blacklist", Config.ReleaseTypes.Blacklist)
+	}
+
+	Config.ReleaseTypes.Blacklist = []torrents.VideoRelease{}
+
+	ExportConfig()
+
+	ImportConfig()
+
+	if Config.ReleaseTypes.Blacklist == nil || len(Config.ReleaseTypes.Blacklist) != 0 {
+		t.Errorf("got %v, want the empty blacklist to be kept", Config.ReleaseTypes.Blacklist)
+	}
+
+	resetConfigs()
+}
+
 func TestExecute(t *testing.T) {
 
 	resetConfigs()
@@ -43,6 +96,13 @@ func TestExecute(t *testing.T) {
 	cmd.MinSize = "12 GB"
 	cmd.Uploaders.Whitelist = []string{"allowed_user1", "allowed_user2"}
 	cmd.Uploaders.Blacklist = []string{"banned_user", "bad_boye"}
+	cmd.MinRelease = torrents.WEBRip
+	cmd.MinAge = "2h"
+	cmd.MaxAge = "1y"
+	cmd.MinSizePerMinute = 20
+	cmd.MaxSizePerMinute = 80
+	cmd.ReleaseTypes.Whitelist = []torrents.VideoRelease{torrents.WEBDL, torrents.BDRip}
+	cmd.ReleaseTypes.Blacklist = []torrents.VideoRelease{torrents.Cam}
 
 	_, err := CaptureCommand(cmd.Execute)
 
@@ -64,6 +124,9 @@ func resetConfigs() {
 	Config.SearchFilters = torrents.SearchFilters{}
 	Config.Uploaders.Whitelist = []string{}
 	Config.Uploaders.Blacklist = []string{}
+	Config.ReleaseTypes.Whitelist = []torrents.VideoRelease{}
+	Config.ReleaseTypes.Blacklist = []torrents.VideoRelease{}
+	Config.Trackers = nil
 
 	ExportConfig()
 }
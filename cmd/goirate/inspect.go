@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"gitlab.com/haath/goirate/pkg/torrents"
+)
+
+// InspectCommand defines the inspect command and holds its options.
+type InspectCommand struct {
+	Args struct {
+		Torrent string `positional-arg-name:"<file | magnet>"`
+	} `positional-args:"1" required:"1"`
+}
+
+// Execute is the callback of the inspect command.
+func (cmd *InspectCommand) Execute(args []string) error {
+
+	var torrentFile *torrents.TorrentFile
+	var err error
+
+	if strings.HasPrefix(cmd.Args.Torrent, "magnet:") {
+
+		torrentFile, err = torrents.TorrentFileFromMagnet(cmd.Args.Torrent)
+
+	} else {
+
+		torrentFile, err = torrents.LoadTorrentFile(cmd.Args.Torrent)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if Options.JSON {
+
+		torrentJSON, err := json.MarshalIndent(torrentFile, "", "   ")
+
+		if err != nil {
+			return err
+		}
+
+		log.Println(string(torrentJSON))
+
+	} else {
+
+		log.Print(getTorrentFileTable(*torrentFile))
+	}
+
+	return nil
+}
+
+func getTorrentFileTable(torrentFile torrents.TorrentFile) string {
+	buf := bytes.NewBufferString("")
+
+	table := tablewriter.NewWriter(buf)
+	table.SetBorder(false)
+	table.SetColumnSeparator("")
+	table.SetAutoWrapText(false)
+
+	table.Append([]string{"Name", torrentFile.Name})
+	table.Append([]string{"Info Hash", torrentFile.InfoHash})
+
+	if len(torrentFile.Files) > 0 {
+
+		table.Append([]string{"Size", torrentFile.SizeString()})
+		table.Append([]string{"Pieces", fmt.Sprintf("%v x %v", torrentFile.PieceCount, torrentFile.PieceLengthString())})
+		table.Append([]string{"Private", fmt.Sprint(torrentFile.Private)})
+	}
+
+	if torrentFile.CreationDate != nil {
+		table.Append([]string{"Created", torrentFile.CreationDate.Format("2006-01-02 15:04:05")})
+	}
+	if torrentFile.CreatedBy != "" {
+		table.Append([]string{"Created By", torrentFile.CreatedBy})
+	}
+	if torrentFile.Comment != "" {
+		table.Append([]string{"Comment", torrentFile.Comment})
+	}
+
+	for i, tracker := range torrentFile.Trackers() {
+
+		label := ""
+		if i == 0 {
+			label = "Trackers"
+		}
+
+		table.Append([]string{label, tracker})
+	}
+
+	table.Render()
+
+	if len(torrentFile.Files) > 0 {
+
+		buf.WriteString("\n")
+
+		filesTable := tablewriter.NewWriter(buf)
+		filesTable.SetHeader([]string{"File", "Size"})
+		filesTable.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT})
+		filesTable.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+		filesTable.SetCenterSeparator("|")
+		filesTable.SetAutoFormatHeaders(false)
+		filesTable.SetAutoWrapText(false)
+
+		for _, file := range torrentFile.Files {
+
+			filesTable.Append([]string{file.Path, file.SizeString()})
+		}
+
+		filesTable.Render()
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gitlab.com/haath/goirate/pkg/torrents"
+)
+
+func TestInspectExecute(t *testing.T) {
+
+	var cmd InspectCommand
+	cmd.Args.Torrent = "../../test_samples/multi.torrent"
+
+	Options.JSON = true
+
+	output, err := CaptureCommand(cmd.Execute)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var torrentFile torrents.TorrentFile
+	json.Unmarshal([]byte(output), &torrentFile)
+
+	if torrentFile.InfoHash != "05c4d891be7fea2390907a7434fcef56538b8b19" || len(torrentFile.Files) != 2 {
+		t.Errorf("got %v", output)
+	}
+
+	Options.JSON = false
+
+	output, err = CaptureCommand(cmd.Execute)
+
+	if err != nil || !strings.Contains(output, "Subs/English.srt") {
+		t.Errorf("got %v, %v", output, err)
+	}
+
+	cmd.Args.Torrent = "magnet:?xt=urn:btih:05c4d891be7fea2390907a7434fcef56538b8b19&dn=Cast+Away"
+
+	output, err = CaptureCommand(cmd.Execute)
+
+	if err != nil || !strings.Contains(output, "Cast Away") {
+		t.Errorf("got %v, %v", output, err)
+	}
+
+	cmd.Args.Torrent = "../../test_samples/missing.torrent"
+
+	if _, err = CaptureCommand(cmd.Execute); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"log"
 	"os"
+	"os/signal"
 	"os/user"
 	"path"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"gitlab.com/haath/goirate/pkg/torrents"
@@ -16,8 +21,9 @@ import (
 // Options holds the command line options for the cli program
 var Options struct {
 	// Options
-	JSON    bool   `short:"j" long:"json" description:"Output in JSON format."`
-	Version func() `long:"version" description:"Show the current version."`
+	JSON    bool          `short:"j" long:"json" description:"Output in JSON format."`
+	Timeout time.Duration `long:"timeout" description:"Abort the command if it has not completed within the given time, such as 30s or 5m."`
+	Version func()        `long:"version" description:"Show the current version."`
 
 	// Commands
 	Config      ConfigCommand      `command:"config" description:"Edit the application's configuration."`
@@ -26,7 +32,9 @@ var Options struct {
 	Series      SeriesCommand      `command:"series" alias:"s" description:"Manage the series watchlist or perform a scan."`
 	Movie       MovieCommand       `command:"movie" alias:"m" description:"Scrape a movie and find torrents for it."`
 	MovieSearch MovieSearchCommand `command:"movie-search" description:"Search IMDb for movies to retrieve their IMDbID and release year."`
+	Inspect     InspectCommand     `command:"inspect" description:"Print out the metadata of a .torrent file or a magnet link."`
 	Update      UpdateCommand      `command:"update" alias:"u" description:"Update the tool."`
+	Cache       CacheCommand       `command:"cache" description:"Clear or inspect the cache of TVDB, OMDb and IMDb responses."`
 }
 
 type torrentSearchArgs struct {
@@ -36,6 +44,9 @@ type torrentSearchArgs struct {
 	Count      uint   `short:"c" long:"count" description:"Limit the number of results."`
 	MagnetLink bool   `long:"only-magnet" description:"Only output magnet links, one on each line."`
 	TorrentURL bool   `long:"only-url" description:"Only output torrent urls, one on each line."`
+	Explain    bool   `long:"explain" description:"Print out how each of the candidate torrents was scored."`
+	Pages      uint   `long:"pages" description:"The number of pages of search results to read on PirateBay mirrors."`
+	MaxResults uint   `long:"max-results" description:"Stop reading further pages of search results on PirateBay mirrors once this many torrents have been found."`
 }
 
 type positionalArgs struct {
@@ -52,11 +63,12 @@ func (a torrentSearchArgs) GetScraper(query string) (torrents.PirateBayScaper, e
 
 	if a.Mirror != "" {
 
-		scraper = torrents.NewScraper(a.Mirror)
+		scraper = a.GetMirrorFilters().Scraper(a.Mirror)
 
 	} else {
 
 		mirrorScraper := GetMirrorScraper()
+		mirrorScraper.SetContext(commandContext())
 
 		if a.SourceURL != "" {
 			mirrorScraper.SetProxySourceURL(a.SourceURL)
@@ -69,7 +81,7 @@ func (a torrentSearchArgs) GetScraper(query string) (torrents.PirateBayScaper, e
 			return nil, err
 		}
 
-		scraper = torrents.NewScraper(mirror.URL)
+		scraper = a.GetMirrorFilters().Scraper(mirror.URL)
 	}
 
 	return scraper, nil
@@ -81,19 +93,7 @@ func (a torrentSearchArgs) GetTorrents(query string) ([]torrents.Torrent, error)
 		return nil, errors.New("too many flags specifying the kind of output")
 	}
 
-	if a.Mirror != "" {
-
-		scraper := torrents.NewScraper(a.Mirror)
-		return scraper.Search(query)
-	}
-
-	mirrorScraper := GetMirrorScraper()
-
-	if a.SourceURL != "" {
-		mirrorScraper.SetProxySourceURL(a.SourceURL)
-	}
-
-	return mirrorScraper.GetTorrents(query)
+	return a.GetFilters().SearchTorrents(query)
 }
 
 func (a *torrentSearchArgs) ValidOutputFlags() bool {
@@ -116,6 +116,13 @@ func (a torrentSearchArgs) GetFilters() *torrents.SearchFilters {
 
 	ApplyConfig(&a.SearchFilters)
 
+	a.SearchFilters.MirrorFilters = a.GetMirrorFilters()
+	a.SearchFilters.MirrorStatsPath = mirrorStatsPath()
+	a.SearchFilters.ProxyListCache = proxyListCachePath()
+	a.SearchFilters.Sources = Config.TorrentSources
+	a.SearchFilters.Scoring = Config.Scoring
+	a.SearchFilters.Context = commandContext()
+
 	if a.Mirror != "" {
 		a.SearchFilters.MirrorURL = a.Mirror
 	}
@@ -126,6 +133,56 @@ func (a torrentSearchArgs) GetFilters() *torrents.SearchFilters {
 	return &a.SearchFilters
 }
 
+// GetMirrorFilters returns the configured filters of the PirateBay mirrors, with the pagination overridden by the flags.
+func (a torrentSearchArgs) GetMirrorFilters() torrents.MirrorFilters {
+
+	filters := Config.TPBMirrors
+
+	if a.Pages > 0 {
+		filters.Pages = int(a.Pages)
+	}
+	if a.MaxResults > 0 {
+		filters.MaxResults = int(a.MaxResults)
+	}
+
+	return filters
+}
+
+var (
+	commandCtx  context.Context
+	commandOnce sync.Once
+)
+
+// commandContext returns the context of the running command, which is cancelled once the program is interrupted
+// or the --timeout expires, so that any outstanding requests are aborted. A second interrupt exits immediately.
+func commandContext() context.Context {
+
+	commandOnce.Do(func() {
+
+		var cancel context.CancelFunc
+
+		if Options.Timeout > 0 {
+			commandCtx, cancel = context.WithTimeout(context.Background(), Options.Timeout)
+		} else {
+			commandCtx, cancel = context.WithCancel(context.Background())
+		}
+
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+
+		go func() {
+
+			<-interrupts
+
+			// Restore the default behavior, so that the next interrupt terminates the program.
+			signal.Stop(interrupts)
+			cancel()
+		}()
+	})
+
+	return commandCtx
+}
+
 func configDir() string {
 
 	usr, usrErr := user.Current()
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"strings"
 
@@ -13,14 +14,22 @@ import (
 // MirrorsCommand defines the mirrors command and holds its options.
 type MirrorsCommand struct {
 	SourceURL string `short:"s" long:"source" description:"Link to a list of PirateBay proxies. Default: proxybay.github.io"`
+	Stats     bool   `long:"stats" description:"Print out the statistics of the searches on each mirror, instead of the list of mirrors."`
 }
 
 // Execute is the callback of the mirrors command.
 func (m *MirrorsCommand) Execute(args []string) error {
 
-	var scraper torrents.MirrorScraper
+	if m.Stats {
+		return m.printStats()
+	}
+
+	scraper := GetMirrorScraper()
 
-	scraper.SetProxySourceURL(m.SourceURL)
+	if m.SourceURL != "" {
+		scraper.SetProxySourceURL(m.SourceURL)
+	}
+	scraper.SetContext(commandContext())
 
 	mirrors, err := scraper.GetMirrors()
 
@@ -66,3 +75,66 @@ func getMirrorsTable(mirrors []torrents.Mirror) string {
 
 	return buf.String()
 }
+
+func (m *MirrorsCommand) printStats() error {
+
+	stats, err := GetMirrorScraper().GetStats()
+
+	if err != nil {
+		return err
+	}
+
+	if Options.JSON {
+		statsJSON, err := json.MarshalIndent(stats.List(), "", "   ")
+
+		if err != nil {
+			return err
+		}
+
+		log.Println(string(statsJSON))
+	} else {
+		log.Printf(getMirrorStatsTable(stats.List(), Config.TPBMirrors))
+	}
+
+	return nil
+}
+
+func getMirrorStatsTable(stats []torrents.MirrorStats, filters torrents.MirrorFilters) string {
+	buf := bytes.NewBufferString("")
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"URL", "Success Rate", "Latency", "Results", "Last Failure", "Status"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+	table.SetCenterSeparator("|")
+	table.SetAutoFormatHeaders(false)
+
+	for _, s := range stats {
+
+		lastFailure := ""
+		if !s.LastFailure.IsZero() {
+			lastFailure = s.LastFailure.Local().Format("2006-01-02 15:04")
+		}
+
+		status := "ok"
+		if s.Blacklisted(filters.GetMaxFailures()) {
+			status = "skipped"
+		} else if s.ConsecutiveFailures > 0 {
+			status = fmt.Sprintf("%v failures", s.ConsecutiveFailures)
+		}
+
+		table.Append([]string{
+			s.URL,
+			fmt.Sprintf("%.0f%% of %v", s.SuccessRate()*100, s.Successes+s.Failures),
+			s.AverageLatency().String(),
+			fmt.Sprintf("%.1f", s.AverageResults()),
+			lastFailure,
+			status,
+		})
+	}
+
+	table.Render()
+
+	return buf.String()
+}
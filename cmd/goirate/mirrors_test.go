@@ -2,7 +2,9 @@ package main
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"gitlab.com/haath/goirate/pkg/torrents"
 )
@@ -26,6 +28,33 @@ func TestMirrorsExecute(t *testing.T) {
 	}
 }
 
+func TestMirrorsExecuteStatic(t *testing.T) {
+
+	defer func(mirrors torrents.MirrorFilters) { Config.TPBMirrors = mirrors }(Config.TPBMirrors)
+	Config.TPBMirrors.Static = []string{"https://static.example.org"}
+
+	// The proxy list cannot be fetched from an invalid host, so only the static mirror is listed.
+	cmd := MirrorsCommand{SourceURL: "http://proxies.invalid/"}
+	Options.JSON = true
+	defer func() { Options.JSON = false }()
+
+	output, err := CaptureCommand(cmd.Execute)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mirrors []torrents.Mirror
+
+	if err := json.Unmarshal([]byte(output), &mirrors); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mirrors) != 1 || mirrors[0].URL != "https://static.example.org" {
+		t.Errorf("got %v, want the static mirror", mirrors)
+	}
+}
+
 func TestGetMirrorsTable(t *testing.T) {
 	var table = []struct {
 		in  []torrents.Mirror
@@ -42,3 +71,23 @@ func TestGetMirrorsTable(t *testing.T) {
 		}
 	}
 }
+
+func TestGetMirrorStatsTable(t *testing.T) {
+
+	stats := []torrents.MirrorStats{
+		{URL: "https://a.org", Successes: 3, Failures: 1, TotalLatencyMS: 900, TotalResults: 60},
+		{URL: "https://b.org", Failures: 5, ConsecutiveFailures: 5, LastFailure: time.Now()},
+	}
+
+	table := getMirrorStatsTable(stats, torrents.MirrorFilters{})
+
+	for _, want := range []string{"https://a.org", "75% of 4", "300ms", "20.0", "https://b.org", "skipped"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("missing %v in:\n%v", want, table)
+		}
+	}
+
+	if table := getMirrorStatsTable(stats, torrents.MirrorFilters{MaxFailures: -1}); strings.Contains(table, "skipped") {
+		t.Errorf("mirrors should not be skipped when blacklisting is disabled:\n%v", table)
+	}
+}
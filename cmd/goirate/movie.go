@@ -37,16 +37,24 @@ func (m *MovieCommand) Execute(args []string) error {
 
 	if !m.NoTorrent {
 
-		perQualityTorrents, err = movie.GetTorrents(*m.GetFilters())
+		filters := movie.SearchFilters(*m.GetFilters())
+
+		perQualityTorrents, err = movie.GetTorrents(filters)
 
 		if len(perQualityTorrents) > 0 {
 
-			topTorrent, err = torrents.PickVideoTorrent(perQualityTorrents, *m.GetFilters())
+			topTorrent, err = torrents.PickVideoTorrent(perQualityTorrents, filters)
 
 			if err != nil {
 				return err
 			}
 
+			if m.Explain {
+
+				scored, _ := torrents.RankVideoTorrents(perQualityTorrents, filters)
+				log.Print(getScoresTable(scored))
+			}
+
 			if m.Download && topTorrent != nil {
 
 				// Send the torrent to the qBittorrent daemon for download
@@ -145,7 +153,7 @@ func (m *MovieCommand) getMovie() (*movies.Movie, error) {
 	var err error
 	var imdbID string
 
-	omdb := Config.OMDBCredentials
+	omdb := Config.OMDBCredentials.WithContext(commandContext())
 
 	if movies.IsIMDbID(m.Args.Query) {
 
@@ -185,7 +193,7 @@ func (m *MovieCommand) searchMovie() (string, error) {
 	var searchResults []movies.MovieID
 	var err error
 
-	omdb := Config.OMDBCredentials
+	omdb := Config.OMDBCredentials.WithContext(commandContext())
 
 	if omdb.IsEnabled() {
 
@@ -24,7 +24,7 @@ func (c *MovieSearchCommand) Execute(args []string) error {
 	var searchResult []movies.MovieID
 	var err error
 
-	omdb := Config.OMDBCredentials
+	omdb := Config.OMDBCredentials.WithContext(commandContext())
 
 	if omdb.IsEnabled() {
 
@@ -1,11 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
-
-	"github.com/imerkle/go-qbittorrent/qbt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
 )
 
+// qbittorrentTimeout is the time limit for each request to the qBittorrent daemon, so that an unreachable daemon
+// does not hang the command.
+const qbittorrentTimeout = 30 * time.Second
+
 // QBittorrentConfig holds the configuration and credentials for communicating with the
 // qBittorrent daemon RPC service.
 type QBittorrentConfig struct {
@@ -14,52 +27,112 @@ type QBittorrentConfig struct {
 	Password string `toml:"password"`
 }
 
-// QBittorrentClient is a simple wrapper of the underlying go-qbittorrent client.
+// QBittorrentClient communicates with the Web API of the qBittorrent daemon.
+// Its requests go through the download proxy, without affecting the other HTTP clients of the process.
 type QBittorrentClient struct {
-	*qbt.Client
+	url  string
+	http *http.Client
 }
 
 // GetClient returns a qBittorrent HTTP client connected with the given configuration.
-func (cfg *QBittorrentConfig) GetClient() (client *QBittorrentClient, err error) {
+func (cfg *QBittorrentConfig) GetClient() (*QBittorrentClient, error) {
+
+	transport, err := utils.ProxyTransport(utils.DownloadRequests)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The session cookie received on login authenticates the later requests.
+	jar, err := cookiejar.New(nil)
+
+	if err != nil {
+		return nil, err
+	}
 
-	qb := qbt.NewClient(cfg.URL)
+	client := &QBittorrentClient{
+		url: strings.TrimSuffix(cfg.URL, "/"),
+		http: &http.Client{
+			Timeout:   qbittorrentTimeout,
+			Transport: transport,
+			Jar:       jar,
+		},
+	}
 
 	if cfg.Username != "" {
 
-		loginOpts := qbt.LoginOptions{
-			Username: cfg.Username,
-			Password: cfg.Password,
+		if err := client.login(cfg.Username, cfg.Password); err != nil {
+			return nil, err
 		}
+	}
+
+	return client, nil
+}
 
-		err = qb.Login(loginOpts)
+func (client *QBittorrentClient) login(username, password string) error {
+
+	form := url.Values{
+		"username": {username},
+		"password": {password},
 	}
 
-	client = &QBittorrentClient{Client: qb}
+	body, err := client.post("/api/v2/auth/login", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
 
-	return
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("qBittorrent login failed: %s", strings.TrimSpace(string(body)))
+	}
+
+	return nil
 }
 
 // AddTorrent sends the given magnet link to the qBittorrent daemon and begins its download,
 // configuring the downloaded files to be placed at the specified output directory.
 func (client *QBittorrentClient) AddTorrent(magnetLink, downloadDir string) error {
 
-	options := map[string]string{
-		"savepath": downloadDir,
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("urls", magnetLink)
+	writer.WriteField("savepath", downloadDir)
+
+	if err := writer.Close(); err != nil {
+		return err
 	}
 
-	resp, err := client.DownloadFromLink(magnetLink, options)
+	_, err := client.post("/api/v2/torrents/add", writer.FormDataContentType(), &body)
+
+	return err
+}
 
-	defer resp.Body.Close()
+// post sends a request to the given endpoint of the Web API, returning the body of the response.
+func (client *QBittorrentClient) post(endpoint, contentType string, body io.Reader) ([]byte, error) {
+
+	request, err := http.NewRequestWithContext(commandContext(), "POST", client.url+endpoint, body)
 
 	if err != nil {
+		return nil, err
+	}
 
-		return err
+	request.Header.Set("Content-Type", contentType)
+
+	// The Web API rejects requests whose Referer does not match its host.
+	request.Header.Set("Referer", client.url)
+
+	res, err := client.http.Do(request)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
+	defer res.Body.Close()
 
-		err = fmt.Errorf("qBittorrent HTTP error: %s", resp.Status)
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("qBittorrent HTTP error: %s", res.Status)
 	}
 
-	return err
+	return ioutil.ReadAll(res.Body)
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+func TestQBittorrentAddTorrent(t *testing.T) {
+
+	var added, savepath string
+
+	// The daemon is reached through the download proxy, which receives the full URLs of the requests.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.String() {
+
+		case "http://qbittorrent.invalid/api/v2/auth/login":
+
+			if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+				w.Write([]byte("Fails."))
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session", Path: "/"})
+			w.Write([]byte("Ok."))
+
+		case "http://qbittorrent.invalid/api/v2/torrents/add":
+
+			if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "session" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			added = r.FormValue("urls")
+			savepath = r.FormValue("savepath")
+			w.Write([]byte("Ok."))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer proxy.Close()
+
+	if err := utils.SetProxies(utils.ProxySettings{Download: utils.ProxyConfig{URL: proxy.URL}}); err != nil {
+		t.Fatal(err)
+	}
+	defer utils.SetProxies(utils.ProxySettings{})
+
+	cfg := QBittorrentConfig{URL: "http://qbittorrent.invalid/", Username: "admin", Password: "wrong"}
+
+	if _, err := cfg.GetClient(); err == nil {
+		t.Errorf("expected an error for wrong credentials")
+	}
+
+	cfg.Password = "secret"
+
+	client, err := cfg.GetClient()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.AddTorrent("magnet:?xt=urn:btih:test", "/downloads/movies"); err != nil {
+		t.Fatal(err)
+	}
+
+	if added != "magnet:?xt=urn:btih:test" || savepath != "/downloads/movies" {
+		t.Errorf("got %v in %v, want the magnet link in /downloads/movies", added, savepath)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"gitlab.com/haath/goirate/pkg/torrents"
@@ -26,6 +28,10 @@ func (m *SearchCommand) Execute(args []string) error {
 
 	torrents = m.GetFilters().FilterTorrentsCount(torrents, m.Count)
 
+	if m.Explain {
+		log.Print(getScoresTable(m.GetFilters().ScoreTorrents(torrents)))
+	}
+
 	if Options.JSON {
 		torrentsJSON, err := json.MarshalIndent(torrents, "", "   ")
 
@@ -78,3 +84,32 @@ func getTorrentsTable(torrents []torrents.Torrent) string {
 
 	return buf.String()
 }
+
+func getScoresTable(scored []torrents.ScoredTorrent) string {
+	buf := bytes.NewBufferString("")
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Score", "Torrent"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_DEFAULT})
+	table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: false, Bottom: false})
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowLine(true)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+
+	for _, torrent := range scored {
+
+		lines := []string{torrent.Torrent.Title}
+
+		for _, factor := range torrent.Factors {
+			lines = append(lines, "  "+factor.String())
+		}
+
+		table.Append([]string{fmt.Sprintf("%.2f", torrent.Score), strings.Join(lines, "\n")})
+	}
+
+	table.Render()
+
+	return buf.String()
+}
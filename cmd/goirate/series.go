@@ -16,6 +16,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/olekukonko/tablewriter"
+	"gitlab.com/haath/goirate/pkg/movies"
 	"gitlab.com/haath/goirate/pkg/series"
 	"gitlab.com/haath/goirate/pkg/torrents"
 	"gitlab.com/haath/goirate/pkg/utils"
@@ -34,8 +35,16 @@ type addCommand struct {
 	LastEpisode      string                `long:"last-episode" short:"e" description:"The last episode that came out."`
 	MinQuality       torrents.VideoQuality `long:"min-quality" description:"The minimum video quality to accept when scanning for torrents of this series."`
 	VerifiedUploader bool                  `long:"trusted" description:"Only accepted torrents from trusted or verified uploaders for this series."`
-	Force            bool                  `long:"force" short:"f" description:"Overwrite this series if it already exists in the watchlist."`
-	Show             bool                  `long:"ls" description:"Execute the show command after adding."`
+	Absolute         bool                  `long:"absolute" description:"Search for torrents of this series using absolute episode numbers, as is common for anime."`
+	Release          torrents.ReleaseFilters
+	Filter           string   `long:"filter" description:"Only accept torrents matching this filter expression when scanning for torrents of this series."`
+	Aliases          []string `long:"alias" description:"Also search for torrents of this series using this title."`
+	MustContain      []string `long:"must-contain" description:"Only accept torrents of this series whose title contains this word."`
+	MustNotContain   []string `long:"must-not-contain" description:"Reject torrents of this series whose title contains this word."`
+	MustMatch        []string `long:"must-match" description:"Only accept torrents of this series whose title matches this regular expression."`
+	MustNotMatch     []string `long:"must-not-match" description:"Reject torrents of this series whose title matches this regular expression."`
+	Force            bool     `long:"force" short:"f" description:"Overwrite this series if it already exists in the watchlist."`
+	Show             bool     `long:"ls" description:"Execute the show command after adding."`
 	Args             struct {
 		Title string `positional-arg-name:"<title | imdbID>"`
 	} `positional-args:"1" required:"1"`
@@ -68,6 +77,30 @@ type seriesTorrents struct {
 // Execute is the callback of the series add command.
 func (cmd *addCommand) Execute(args []string) error {
 
+	if cmd.Filter != "" {
+		if _, err := torrents.ParseFilterExpression(cmd.Filter); err != nil {
+			return err
+		}
+	}
+
+	titleFilters := torrents.TitleFilters{
+		MustContain:    cmd.MustContain,
+		MustNotContain: cmd.MustNotContain,
+		MustMatch:      cmd.MustMatch,
+		MustNotMatch:   cmd.MustNotMatch,
+	}
+
+	if err := titleFilters.Validate(); err != nil {
+		return err
+	}
+
+	// A plain number is only accepted for series with absolute numbering, since it is not a season and episode.
+	absoluteEpisode, absoluteErr := series.ParseAbsoluteEpisodeString(cmd.LastEpisode)
+
+	if cmd.LastEpisode != "" && absoluteErr == nil && !cmd.Absolute {
+		return fmt.Errorf("unable to parse the last episode number from: %v, use the S03E07 format or --absolute for absolute numbering", cmd.LastEpisode)
+	}
+
 	tvdbToken, err := tvdbLogin()
 
 	if err != nil {
@@ -91,7 +124,15 @@ func (cmd *addCommand) Execute(args []string) error {
 
 		episode = series.ParseEpisodeString(cmd.LastEpisode)
 
-		if episode.Season == 0 && episode.Episode == 0 {
+		if absoluteErr == nil {
+
+			episode, err = tvdbToken.AbsoluteEpisode(seriesID, absoluteEpisode.Absolute)
+
+			if err != nil {
+				return err
+			}
+
+		} else if episode.Season == 0 && episode.Episode == 0 {
 
 			return fmt.Errorf("unable to parse the last episode number from: %v", cmd.LastEpisode)
 		}
@@ -105,12 +146,34 @@ func (cmd *addCommand) Execute(args []string) error {
 		}
 	}
 
+	var imdbID string
+
+	if movies.IsIMDbURL(cmd.Args.Title) {
+
+		imdbID, _ = movies.ExtractIMDbID(cmd.Args.Title)
+
+	} else if movies.IsIMDbID(cmd.Args.Title) {
+
+		imdbID, _ = movies.FormatIMDbID(cmd.Args.Title)
+
+	} else {
+
+		// The IMDb ID is only used by some torrent sources, so the series can be added without one.
+		imdbID, _ = tvdbToken.GetIMDbID(seriesID)
+	}
+
 	ser := series.Series{
-		ID:               seriesID,
-		Title:            seriesName,
-		MinQuality:       cmd.MinQuality,
-		VerifiedUploader: cmd.VerifiedUploader,
-		LastEpisode:      episode,
+		ID:                seriesID,
+		Title:             seriesName,
+		IMDbID:            imdbID,
+		MinQuality:        cmd.MinQuality,
+		VerifiedUploader:  cmd.VerifiedUploader,
+		AbsoluteNumbering: cmd.Absolute,
+		ReleaseFilters:    cmd.Release,
+		Filter:            cmd.Filter,
+		Aliases:           cmd.Aliases,
+		TitleFilters:      titleFilters,
+		LastEpisode:       episode,
 	}
 	ser.Actions.Emails = []string{}
 
@@ -207,8 +270,22 @@ func (cmd *scanCommand) Execute(args []string) error {
 
 	for i := range seriesList {
 
+		if commandContext().Err() != nil {
+			// The scan was interrupted, but the torrents found so far are still handled.
+			break
+		}
+
 		ser := &seriesList[i]
 
+		// Series added before their IMDb ID was stored get it on their next scan, and keep it once the
+		// watchlist is stored, so that the sources which search by IMDb ID apply to them as well.
+		if ser.IMDbID == "" {
+
+			if imdbID, err := tvdbToken.GetIMDbID(ser.ID); err == nil {
+				ser.IMDbID = imdbID
+			}
+		}
+
 		found := true
 
 		for found && (cmd.Count == 0 || seriesTorrentCount(torrentList) < cmd.Count) {
@@ -224,6 +301,17 @@ func (cmd *scanCommand) Execute(args []string) error {
 	if !cmd.DryRun && !cmd.NoUpdate {
 
 		storeSeries(seriesList)
+
+	} else if !cmd.DryRun {
+
+		// The episodes are left as they were, but the IMDb IDs that were looked up are still stored.
+		stored := loadSeries()
+
+		for i := range stored {
+			stored[i].IMDbID = seriesList[i].IMDbID
+		}
+
+		storeSeries(stored)
 	}
 
 	if Options.JSON {
@@ -249,7 +337,7 @@ func (cmd *scanCommand) Execute(args []string) error {
 		enableOutput()
 	}
 
-	return nil
+	return commandContext().Err()
 }
 
 func (cmd *scanCommand) scanSeries(tvdbToken *series.TVDBToken, ser *series.Series, torrentList *[]seriesTorrents) (bool, error) {
@@ -260,6 +348,12 @@ func (cmd *scanCommand) scanSeries(tvdbToken *series.TVDBToken, ser *series.Seri
 		filters.MinQuality = ser.MinQuality
 	}
 	filters.VerifiedUploader = filters.VerifiedUploader || ser.VerifiedUploader
+	filters.Release = filters.Release.Merge(ser.ReleaseFilters)
+	filters.Filter = torrents.CombineFilterExpressions(filters.Filter, ser.Filter)
+
+	if err := filters.CompileFilter(); err != nil {
+		return false, err
+	}
 
 	nextEpisode, err := ser.NextEpisode(tvdbToken)
 
@@ -278,6 +372,11 @@ func (cmd *scanCommand) scanSeries(tvdbToken *series.TVDBToken, ser *series.Seri
 		log.Printf("Searching for: %s %s\n", ser.Title, nextEpisode)
 	}
 
+	// The runtime is used to score the size of torrents, and to check their size per minute.
+	if runtime, err := tvdbToken.GetRuntime(ser.ID); err == nil {
+		filters.Runtime = runtime
+	}
+
 	allTorrents, err := ser.GetTorrents(*filters, nextEpisode)
 
 	if err != nil {
@@ -287,6 +386,12 @@ func (cmd *scanCommand) scanSeries(tvdbToken *series.TVDBToken, ser *series.Seri
 
 	torrent, err := torrents.PickVideoTorrent(allTorrents, *filters)
 
+	if cmd.Explain {
+
+		scored, _ := torrents.RankVideoTorrents(allTorrents, *filters)
+		log.Print(getScoresTable(scored))
+	}
+
 	if err != nil || torrent == nil {
 
 		return false, err
@@ -573,7 +678,7 @@ func tvdbLogin() (*series.TVDBToken, error) {
 		return nil, fmt.Errorf("the series command requires valid credentials for the TVDB API to be configured at %v\nthey can be obtained by making a free account at https://www.thetvdb.com/", configPath())
 	}
 
-	tkn, err := cred.Login()
+	tkn, err := cred.LoginContext(commandContext())
 
 	if err != nil {
 		return nil, errors.New("Error logging into the TVDB API.: " + err.Error())
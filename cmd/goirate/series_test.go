@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gitlab.com/haath/goirate/pkg/series"
+	"gitlab.com/haath/goirate/pkg/torrents"
 )
 
 func TestStoreLoadSeries(t *testing.T) {
@@ -17,6 +18,11 @@ func TestStoreLoadSeries(t *testing.T) {
 		Title:       "Super awesome show",
 		LastEpisode: series.Episode{Season: 5, Episode: 11},
 		MinQuality:  "720p",
+		Aliases:     []string{"Super awesome show (US)"},
+		TitleFilters: torrents.TitleFilters{
+			MustContain:  []string{"US"},
+			MustNotMatch: []string{`\bUK\b`},
+		},
 	}
 
 	storeSeries([]series.Series{ser})
@@ -223,3 +229,15 @@ func TestEpisodeRangeString(t *testing.T) {
 		})
 	}
 }
+
+func TestAddPlainEpisodeNumber(t *testing.T) {
+
+	var addCmd addCommand
+	addCmd.Args.Title = "One Piece"
+	addCmd.LastEpisode = "5"
+
+	// The episode is rejected before the TVDB is reached, since the series is not added with --absolute.
+	if err := addCmd.Execute([]string{}); err == nil || !strings.Contains(err.Error(), "--absolute") {
+		t.Errorf("got %v, want an error suggesting --absolute", err)
+	}
+}
@@ -222,7 +222,8 @@ func GetMovie(imdbID string) (*Movie, error) {
 		return nil, err
 	}
 
-	doc, err := utils.HTTPGet(url.String())
+	client := utils.HTTPClient{Category: utils.MetadataRequests, CacheTTL: MovieCacheTTL}
+	doc, err := client.Get(url.String())
 
 	if err != nil {
 		return nil, err
@@ -237,7 +238,8 @@ func GetMovie(imdbID string) (*Movie, error) {
 func Search(query string) ([]MovieID, error) {
 
 	url := searchURL(query)
-	doc, err := utils.HTTPGet(url)
+	client := utils.HTTPClient{Category: utils.MetadataRequests, CacheTTL: SearchCacheTTL}
+	doc, err := client.Get(url)
 
 	if err != nil {
 		return nil, err
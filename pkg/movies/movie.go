@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"gitlab.com/haath/goirate/pkg/torrents"
 )
@@ -42,6 +43,11 @@ func (m MovieID) GetURL() (*url.URL, error) {
 	return url.Parse(urlString)
 }
 
+// Runtime returns the duration of the movie.
+func (m Movie) Runtime() time.Duration {
+	return time.Duration(m.Duration) * time.Minute
+}
+
 // FormattedDuration returns the duration of the movie in human-readable format.
 func (m Movie) FormattedDuration() string {
 
@@ -102,7 +108,21 @@ func (m Movie) GetSearchQuery(useAltTitle bool) string {
 	return searchQuery
 }
 
-// GetTorrent will search The Pirate Bay and return the best torrent that complies with the given filters.
+// SearchFilters returns a copy of the given filters, completed with the IMDb ID and the runtime of the movie
+// when they are known.
+func (m Movie) SearchFilters(filters torrents.SearchFilters) torrents.SearchFilters {
+
+	if imdbID, err := FormatIMDbID(m.IMDbID); err == nil {
+		filters.IMDbID = imdbID
+	}
+	if m.Duration > 0 {
+		filters.Runtime = m.Runtime()
+	}
+
+	return filters
+}
+
+// GetTorrent will search the enabled torrent sources and return the best torrent that complies with the given filters.
 func (m Movie) GetTorrent(filters torrents.SearchFilters) (*torrents.Torrent, error) {
 
 	filteredTorrents, err := m.GetTorrents(filters)
@@ -111,13 +131,16 @@ func (m Movie) GetTorrent(filters torrents.SearchFilters) (*torrents.Torrent, er
 		return nil, err
 	}
 
-	return torrents.PickVideoTorrent(filteredTorrents, filters)
+	return torrents.PickVideoTorrent(filteredTorrents, m.SearchFilters(filters))
 }
 
-// GetTorrents will search The Pirate Bay for torrents of this movie that comply with the given filters.
+// GetTorrents will search the enabled torrent sources for torrents of this movie that comply with the given filters.
+// Sources that support it will look up the movie by its IMDb ID, while the rest will be searched by its title.
 // It will return one torrent for each video quality.
 func (m Movie) GetTorrents(filters torrents.SearchFilters) ([]torrents.Torrent, error) {
 
+	filters = m.SearchFilters(filters)
+
 	filters.SearchTerms = m.GetSearchTerms(false)
 	trnts, err := filters.SearchVideoTorrents(m.GetSearchQuery(false))
 
@@ -2,6 +2,7 @@ package movies
 
 import (
 	"testing"
+	"time"
 
 	"gitlab.com/haath/goirate/pkg/torrents"
 )
@@ -49,6 +50,25 @@ func TestFormattedDuration(t *testing.T) {
 	}
 }
 
+func TestSearchFilters(t *testing.T) {
+	table := []struct {
+		movie   Movie
+		imdbID  string
+		runtime time.Duration
+	}{
+		{Movie{MovieID: MovieID{IMDbID: "1028576"}, Duration: 143}, "tt1028576", 143 * time.Minute},
+		{Movie{MovieID: MovieID{IMDbID: "-123"}}, "", 90 * time.Minute},
+	}
+	for _, tt := range table {
+		t.Run(tt.movie.IMDbID, func(t *testing.T) {
+			filters := tt.movie.SearchFilters(torrents.SearchFilters{Runtime: 90 * time.Minute})
+			if filters.IMDbID != tt.imdbID || filters.Runtime != tt.runtime {
+				t.Errorf("got %v and %v, want %v and %v", filters.IMDbID, filters.Runtime, tt.imdbID, tt.runtime)
+			}
+		})
+	}
+}
+
 func TestGetTorrent(t *testing.T) {
 	table := []struct {
 		in Movie
@@ -1,11 +1,13 @@
 package movies
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"gitlab.com/haath/goirate/pkg/utils"
 )
@@ -16,9 +18,26 @@ const (
 	baseEndpoint apiEndpoint = "https://www.omdbapi.com"
 )
 
+// The time for which the responses of the OMDb API and IMDb are cached, when a cache has been set through utils.SetCache.
+var (
+	// MovieCacheTTL applies to the details of a movie.
+	MovieCacheTTL = 7 * 24 * time.Hour
+	// SearchCacheTTL applies to searches for movies.
+	SearchCacheTTL = 24 * time.Hour
+)
+
 // OMDBCredentials holds the API key for access to the OMDB API.
 type OMDBCredentials struct {
 	APIKey string `toml:"api_key"`
+	ctx    context.Context
+}
+
+// WithContext returns a copy of the credentials, whose API calls are aborted once the given context is cancelled or expires.
+func (omdb OMDBCredentials) WithContext(ctx context.Context) *OMDBCredentials {
+
+	omdb.ctx = ctx
+
+	return &omdb
 }
 
 // IsEnabled returns true if an API key has been provided for the OMDb API.
@@ -60,7 +79,7 @@ func (omdb *OMDBCredentials) GetMovie(imdbID string) (*Movie, error) {
 
 	reqURL := fmt.Sprintf("%v&i=%v", baseURL, formattedID)
 
-	httpClient := utils.HTTPClient{}
+	httpClient := utils.HTTPClient{Category: utils.MetadataRequests, Context: omdb.ctx, CacheTTL: MovieCacheTTL}
 
 	err = httpClient.GetJSON(reqURL, &omdbMovieResponse)
 
@@ -110,7 +129,7 @@ func (omdb *OMDBCredentials) Search(query string) ([]MovieID, error) {
 
 	reqURL := fmt.Sprintf("%v&s=%v", baseURL, formattedQuery)
 
-	httpClient := utils.HTTPClient{}
+	httpClient := utils.HTTPClient{Category: utils.MetadataRequests, Context: omdb.ctx, CacheTTL: SearchCacheTTL}
 
 	err = httpClient.GetJSON(reqURL, &omdbSearchResponse)
 
@@ -10,11 +10,14 @@ import (
 
 // Episode represents a unique episode of a series, identified by a
 // pair of a season and episode number.
+// Series which are released with absolute episode numbers, such as anime,
+// also keep track of the episode's absolute number.
 type Episode struct {
-	Season  uint       `toml:"season" json:"season"`
-	Episode uint       `toml:"episode" json:"episode"`
-	Title   string     `toml:"title" json:"title"`
-	Aired   *time.Time `toml:"aired" json:"aired"`
+	Season   uint       `toml:"season" json:"season"`
+	Episode  uint       `toml:"episode" json:"episode"`
+	Absolute uint       `toml:"absolute,omitempty" json:"absolute,omitempty"`
+	Title    string     `toml:"title" json:"title"`
+	Aired    *time.Time `toml:"aired" json:"aired"`
 }
 
 // ParseEpisodeString will extract the season and episode number from a string
@@ -48,6 +51,27 @@ func ParseEpisodeString(episodeStr string) Episode {
 	return episode
 }
 
+// ParseAbsoluteEpisodeString will extract the absolute episode number from a plain number, such as 137 or #137,
+// leaving the season and episode numbers at zero.
+func ParseAbsoluteEpisodeString(episodeStr string) (Episode, error) {
+
+	r, _ := regexp.Compile(`^\s*#?\s*(\d+)\s*$`)
+
+	m := r.FindStringSubmatch(episodeStr)
+
+	if len(m) == 0 {
+		return Episode{}, fmt.Errorf("invalid absolute episode number: %v", episodeStr)
+	}
+
+	a, err := strconv.Atoi(m[1])
+
+	if err != nil || a == 0 {
+		return Episode{}, fmt.Errorf("invalid absolute episode number: %v", episodeStr)
+	}
+
+	return Episode{Absolute: uint(a)}, nil
+}
+
 // IsAfter returns true if this episode is sequentially after the given episode.
 func (ep Episode) IsAfter(episode Episode) bool {
 
@@ -61,6 +85,13 @@ func (ep Episode) String() string {
 	return fmt.Sprintf("S%02dE%02d", ep.Season, ep.Episode)
 }
 
+// AbsoluteString returns the absolute number of an episode, zero-padded to two digits
+// as is customary in the names of anime releases.
+func (ep Episode) AbsoluteString() string {
+
+	return fmt.Sprintf("%02d", ep.Absolute)
+}
+
 // LongString returns the string Season xx Episode yy representation of an episode.
 func (ep Episode) LongString() string {
 
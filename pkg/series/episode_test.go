@@ -39,6 +39,39 @@ func TestParseEpisodeString(t *testing.T) {
 	}
 }
 
+func TestParseAbsoluteEpisodeString(t *testing.T) {
+	table := []struct {
+		in     string
+		out    uint
+		outStr string
+	}{
+		{"137", 137, "137"},
+		{"#7", 7, "07"},
+		{" # 1071 ", 1071, "1071"},
+		{"S05E12", 0, "00"},
+		{"0", 0, "00"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			ep, err := ParseAbsoluteEpisodeString(tt.in)
+
+			if (err != nil) != (tt.out == 0) {
+				t.Errorf("got error %v for %v", err, tt.in)
+			}
+
+			if ep.Absolute != tt.out {
+				t.Errorf("got %v, want %v", ep.Absolute, tt.out)
+			}
+
+			if ep.AbsoluteString() != tt.outStr {
+				t.Errorf("got %v, want %v", ep.AbsoluteString(), tt.outStr)
+			}
+		})
+	}
+}
+
 func TestIsAfter(t *testing.T) {
 
 	table := []struct {
@@ -2,8 +2,11 @@ package series
 
 import (
 	"fmt"
+	"log"
+	"os"
 	"strings"
 
+	"gitlab.com/haath/goirate/pkg/movies"
 	"gitlab.com/haath/goirate/pkg/torrents"
 	"gitlab.com/haath/goirate/pkg/utils"
 )
@@ -11,12 +14,20 @@ import (
 // Series holds the title of a series along
 // with the next episode expected to come out.
 type Series struct {
-	ID               int                    `toml:"id" json:"id"`
-	Title            string                 `toml:"title" json:"title"`
-	MinQuality       torrents.VideoQuality  `toml:"min_quality" json:"min_quality"`
-	VerifiedUploader bool                   `toml:"only_trusted" json:"only_trusted"`
-	LastEpisode      Episode                `toml:"last_episode" json:"last_episode"`
-	Actions          utils.WatchlistActions `toml:"actions" json:"actions"`
+	ID                int                     `toml:"id" json:"id"`
+	Title             string                  `toml:"title" json:"title"`
+	Aliases           []string                `toml:"aliases" json:"aliases,omitempty"`
+	IMDbID            string                  `toml:"imdb_id" json:"imdb_id"`
+	MinQuality        torrents.VideoQuality   `toml:"min_quality" json:"min_quality"`
+	VerifiedUploader  bool                    `toml:"only_trusted" json:"only_trusted"`
+	AbsoluteNumbering bool                    `toml:"absolute_numbering" json:"absolute_numbering"`
+	ReleaseFilters    torrents.ReleaseFilters `toml:"release" json:"release"`
+	Filter            string                  `toml:"filter" json:"filter,omitempty"`
+	LastEpisode       Episode                 `toml:"last_episode" json:"last_episode"`
+	Actions           utils.WatchlistActions  `toml:"actions" json:"actions"`
+
+	// TitleFilters holds the words and patterns that are required or ignored in the titles of this series' torrents.
+	torrents.TitleFilters
 }
 
 // NextEpisode uses the TVDB API to make a best guess as to which is the next episode
@@ -34,7 +45,11 @@ func (s *Series) GetSearchQuery(episode Episode) string {
 
 	var searchQuery string
 
-	if episode.Season == 0 && episode.Episode == 0 {
+	if s.AbsoluteNumbering && episode.Absolute > 0 {
+
+		searchQuery = fmt.Sprintf("%v %v", title, episode.AbsoluteString())
+
+	} else if episode.Season == 0 && episode.Episode == 0 {
 
 		searchQuery = title
 
@@ -57,7 +72,12 @@ func (s *Series) GetSearchTerms(episode Episode) []string {
 
 	searchTerms := []string{title}
 
-	if episode.Season == 0 && episode.Episode == 0 {
+	if s.AbsoluteNumbering && episode.Absolute > 0 {
+
+		// Anime releases are named like "[Group] Title - 137 [1080p]", so the number is expected right after the title.
+		searchTerms = []string{fmt.Sprintf("%v %v", title, episode.AbsoluteString())}
+
+	} else if episode.Season == 0 && episode.Episode == 0 {
 
 		// Add nothing.
 
@@ -73,7 +93,7 @@ func (s *Series) GetSearchTerms(episode Episode) []string {
 	return searchTerms
 }
 
-// GetTorrent will search The Pirate Bay and return the best torrent that complies with the given filters.
+// GetTorrent will search the enabled torrent sources and return the best torrent that complies with the given filters.
 func (s *Series) GetTorrent(filters torrents.SearchFilters, episode Episode) (*torrents.Torrent, error) {
 
 	filteredTorrents, err := s.GetTorrents(filters, episode)
@@ -86,12 +106,83 @@ func (s *Series) GetTorrent(filters torrents.SearchFilters, episode Episode) (*t
 }
 
 // GetTorrents will attempt to find a torrent for an episode of this series.
+// The series is searched for by its title and then by each of its aliases, with the results of all searches merged.
+// Sources that look up the episode by the IMDb ID of the series are only searched once, along with the title.
+// An error is only returned if every search failed.
 func (s *Series) GetTorrents(filters torrents.SearchFilters, episode Episode) ([]torrents.Torrent, error) {
 
-	searchQuery := s.GetSearchQuery(episode)
-	filters.SearchTerms = s.GetSearchTerms(episode)
+	filters.Title = filters.Title.Merge(s.TitleFilters)
+
+	if imdbID, err := movies.FormatIMDbID(s.IMDbID); err == nil {
+
+		// Sources that support it will look up the episode by the series' IMDb ID instead.
+		filters.IMDbID = imdbID
+		filters.Episode = &torrents.EpisodeQuery{Season: episode.Season, Episode: episode.Episode}
+	}
+
+	var allTorrents []torrents.Torrent
+	var lastErr error
+
+	titles := s.Titles()
+	failures := 0
+
+	for i, title := range titles {
+
+		alias := *s
+		alias.Title = title
+
+		filters.SearchTerms = alias.GetSearchTerms(episode)
+		filters.TextSearchOnly = i > 0
+
+		trnts, err := filters.SearchVideoTorrents(alias.GetSearchQuery(episode))
+
+		if err != nil {
+
+			if os.Getenv("GOIRATE_DEBUG") == "true" {
+				log.Printf("failed to search for %v: %v\n", title, err)
+			}
+
+			lastErr = err
+			failures++
+			continue
+		}
+
+		allTorrents = append(allTorrents, trnts...)
+	}
+
+	if failures == len(titles) {
+		return nil, lastErr
+	}
+
+	return torrents.DeduplicateTorrents(allTorrents), nil
+}
+
+// Titles returns the title of the series followed by its aliases, skipping duplicates.
+func (s *Series) Titles() []string {
+
+	normalize := func(title string) string {
+		return utils.NormalizeQuery(utils.NormalizeMediaTitle(title))
+	}
+
+	titles := []string{s.Title}
+
+	for _, alias := range s.Aliases {
+
+		duplicate := false
+
+		for _, title := range titles {
+			if normalize(title) == normalize(alias) {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate && strings.TrimSpace(alias) != "" {
+			titles = append(titles, alias)
+		}
+	}
 
-	return filters.SearchVideoTorrents(searchQuery)
+	return titles
 }
 
 func (s *Series) getNormalizedTitle() string {
@@ -1,7 +1,10 @@
 package series
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"gitlab.com/haath/goirate/pkg/torrents"
@@ -33,6 +36,35 @@ func TestSearchQuery(t *testing.T) {
 	}
 }
 
+func TestAbsoluteSearchQuery(t *testing.T) {
+	table := []struct {
+		absolute bool
+		ep       Episode
+		query    string
+		terms    []string
+	}{
+		{true, Episode{Season: 20, Episode: 36, Absolute: 1071}, "one piece 1071", []string{"One Piece 1071"}},
+		{true, Episode{Season: 1, Episode: 5, Absolute: 5}, "one piece 05", []string{"One Piece 05"}},
+		{true, Episode{Season: 20, Episode: 36}, "one piece s20e36", []string{"One Piece", "S20E36"}},
+		{false, Episode{Season: 20, Episode: 36, Absolute: 1071}, "one piece s20e36", []string{"One Piece", "S20E36"}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.query, func(t *testing.T) {
+
+			series := Series{Title: "One Piece", AbsoluteNumbering: tt.absolute}
+
+			if query := series.GetSearchQuery(tt.ep); query != tt.query {
+				t.Errorf("got %v, want %v", query, tt.query)
+			}
+
+			if terms := series.GetSearchTerms(tt.ep); fmt.Sprint(terms) != fmt.Sprint(tt.terms) {
+				t.Errorf("got %v, want %v", terms, tt.terms)
+			}
+		})
+	}
+}
+
 func TestGetTorrent(t *testing.T) {
 	table := []struct {
 		in Series
@@ -92,3 +124,100 @@ func TestNextEpisode(t *testing.T) {
 		})
 	}
 }
+
+func TestTitles(t *testing.T) {
+	table := []struct {
+		title   string
+		aliases []string
+		out     []string
+	}{
+		{"The Office (US)", nil, []string{"The Office (US)"}},
+		{"The Office (US)", []string{"The Office US", ""}, []string{"The Office (US)", "The Office US"}},
+		{"Marvel's Agents of S.H.I.E.L.D.", []string{"Agents of SHIELD", "agents of shield"}, []string{"Marvel's Agents of S.H.I.E.L.D.", "Agents of SHIELD"}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			series := Series{Title: tt.title, Aliases: tt.aliases}
+
+			if titles := series.Titles(); !reflect.DeepEqual(titles, tt.out) {
+				t.Errorf("got %v, want %v", titles, tt.out)
+			}
+		})
+	}
+}
+
+type countingSource struct {
+	name     string
+	searches *int
+	torrents []torrents.Torrent
+}
+
+func (s *countingSource) Name() string {
+	return s.name
+}
+
+func (s *countingSource) Search(query string) ([]torrents.Torrent, error) {
+
+	*s.searches++
+
+	if strings.Contains(query, "broken") {
+		return nil, errors.New("unreachable")
+	}
+
+	return []torrents.Torrent{{Title: query + " 1080p", Seeders: 10, VideoQuality: torrents.High}}, nil
+}
+
+type countingEpisodeSource struct {
+	countingSource
+	episodeSearches *int
+}
+
+func (s *countingEpisodeSource) SearchEpisode(imdbID string, episode torrents.EpisodeQuery) ([]torrents.Torrent, error) {
+
+	*s.episodeSearches++
+
+	return []torrents.Torrent{{Title: "The.Expanse.S01E02.720p", IMDbID: imdbID, Seeders: 5, VideoQuality: torrents.Medium}}, nil
+}
+
+func TestGetTorrentsAliases(t *testing.T) {
+
+	textSearches, episodeSearches, episodeTextSearches := 0, 0, 0
+
+	torrents.RegisterSource("counting-text", func(filters torrents.SearchFilters) (torrents.Source, error) {
+		return &countingSource{name: "counting-text", searches: &textSearches}, nil
+	})
+	torrents.RegisterSource("counting-episodes", func(filters torrents.SearchFilters) (torrents.Source, error) {
+		return &countingEpisodeSource{countingSource{name: "counting-episodes", searches: &episodeTextSearches}, &episodeSearches}, nil
+	})
+
+	filters := torrents.SearchFilters{Sources: torrents.SourceConfig{Enabled: []string{"counting-text", "counting-episodes"}}}
+
+	ser := Series{Title: "The Expanse", Aliases: []string{"Expanse", "Broken"}, IMDbID: "tt3230854"}
+
+	trnts, err := ser.GetTorrents(filters, Episode{Season: 1, Episode: 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trnts) == 0 {
+		t.Errorf("expected the torrents of the searches that succeeded")
+	}
+	if episodeSearches != 1 {
+		t.Errorf("got %v episode searches, want 1", episodeSearches)
+	}
+	if episodeTextSearches != 0 {
+		t.Errorf("got %v text searches on the episode source, want 0", episodeTextSearches)
+	}
+	if textSearches != 3 {
+		t.Errorf("got %v text searches, want 3", textSearches)
+	}
+
+	// An error is returned when every search failed.
+	ser = Series{Title: "Broken", Aliases: []string{"Broken Show"}}
+
+	if _, err := ser.GetTorrents(filters, Episode{Season: 1, Episode: 2}); err == nil {
+		t.Errorf("expected an error when every search failed")
+	}
+}
@@ -1,9 +1,12 @@
 package series
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gitlab.com/haath/goirate/pkg/movies"
@@ -22,6 +25,7 @@ type TVDBCredentials struct {
 // with the TVDB API.
 type TVDBToken struct {
 	Token string `json:"token"`
+	ctx   context.Context
 }
 
 type apiEndpoint string
@@ -30,9 +34,20 @@ const (
 	baseEndpoint     apiEndpoint = "https://api.thetvdb.com"
 	loginEndpoint    apiEndpoint = baseEndpoint + "/login"
 	searchEndpoint   apiEndpoint = baseEndpoint + "/search/series"
+	seriesEndpoint   apiEndpoint = baseEndpoint + "/series/%v"
 	episodesEndpoint apiEndpoint = baseEndpoint + "/series/%v/episodes"
 )
 
+// The time for which the responses of the TVDB API are cached, when a cache has been set through utils.SetCache.
+var (
+	// SearchCacheTTL applies to searches for series.
+	SearchCacheTTL = 24 * time.Hour
+	// SeriesCacheTTL applies to the details of a series, such as its IMDb ID and runtime.
+	SeriesCacheTTL = 24 * time.Hour
+	// EpisodesCacheTTL applies to the list of the episodes of a series.
+	EpisodesCacheTTL = 6 * time.Hour
+)
+
 func (ep apiEndpoint) String() string {
 	return string(ep)
 }
@@ -41,13 +56,30 @@ func (ep apiEndpoint) String() string {
 // used to obtain a JWT token for authenticating with the rest of the API.
 func (cred *TVDBCredentials) Login() (TVDBToken, error) {
 
-	var tkn TVDBToken
+	return cred.LoginContext(context.Background())
+}
+
+// LoginContext functions like Login, but the login and the API calls made with
+// the returned token are aborted once the given context is cancelled or expires.
+func (cred *TVDBCredentials) LoginContext(ctx context.Context) (TVDBToken, error) {
 
-	err := utils.HTTPPost(loginEndpoint.String(), cred, &tkn)
+	tkn := TVDBToken{ctx: ctx}
+
+	httpClient := utils.HTTPClient{Category: utils.MetadataRequests, Context: ctx}
+
+	err := httpClient.Post(loginEndpoint.String(), cred, &tkn)
 
 	return tkn, err
 }
 
+// WithContext returns a copy of the token, whose API calls are aborted once the given context is cancelled or expires.
+func (tkn TVDBToken) WithContext(ctx context.Context) *TVDBToken {
+
+	tkn.ctx = ctx
+
+	return &tkn
+}
+
 // Search will search the TVDB for the given series name and return its ID.
 func (tkn *TVDBToken) Search(searchName string) (id int, name string, err error) {
 
@@ -86,7 +118,7 @@ func (tkn *TVDBToken) Search(searchName string) (id int, name string, err error)
 		searchURL = fmt.Sprintf("%v?name=%v", searchEndpoint, url.QueryEscape(searchName))
 	}
 
-	err = tkn.apiCall(searchURL, &searchResponse)
+	err = tkn.apiCall(searchURL, &searchResponse, SearchCacheTTL)
 
 	if err == nil && len(searchResponse.Data) > 0 {
 		name = searchResponse.Data[0].SeriesName
@@ -96,6 +128,48 @@ func (tkn *TVDBToken) Search(searchName string) (id int, name string, err error)
 	return
 }
 
+// GetIMDbID uses the TVDB API to retrieve the IMDb ID of a particular series.
+func (tkn *TVDBToken) GetIMDbID(seriesID int) (string, error) {
+
+	var seriesResponse struct {
+		Data struct {
+			IMDbID string `json:"imdbId"`
+		} `json:"data"`
+	}
+
+	err := tkn.apiCall(fmt.Sprintf(seriesEndpoint.String(), seriesID), &seriesResponse, SeriesCacheTTL)
+
+	if err != nil {
+		return "", err
+	}
+
+	return movies.FormatIMDbID(seriesResponse.Data.IMDbID)
+}
+
+// GetRuntime uses the TVDB API to retrieve the runtime of the episodes of a particular series.
+func (tkn *TVDBToken) GetRuntime(seriesID int) (time.Duration, error) {
+
+	var seriesResponse struct {
+		Data struct {
+			Runtime string `json:"runtime"`
+		} `json:"data"`
+	}
+
+	err := tkn.apiCall(fmt.Sprintf(seriesEndpoint.String(), seriesID), &seriesResponse, SeriesCacheTTL)
+
+	if err != nil {
+		return 0, err
+	}
+
+	minutes, err := strconv.Atoi(strings.TrimSpace(seriesResponse.Data.Runtime))
+
+	if err != nil || minutes <= 0 {
+		return 0, fmt.Errorf("the runtime of series %v is unknown", seriesID)
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
+
 // LastEpisode uses the TVDB API to retrieve the last episode that aired
 // for a particular series.
 func (tkn *TVDBToken) LastEpisode(seriesID int) (Episode, error) {
@@ -158,15 +232,44 @@ func (tkn *TVDBToken) NextEpisode(seriesID int, episode Episode) (nextEpisode Ep
 		nextEpisode = curSeasonNext
 	}
 
+	if nextEpisode.Absolute == 0 && episode.Absolute > 0 {
+
+		// The TVDB does not know the absolute numbers of episodes that have not been announced yet.
+		nextEpisode.Absolute = episode.Absolute + 1
+	}
+
 	return
 }
 
+// AbsoluteEpisode uses the TVDB API to find the season and episode number of a series' episode,
+// given its absolute number.
+func (tkn *TVDBToken) AbsoluteEpisode(seriesID int, absolute uint) (Episode, error) {
+
+	var episode Episode
+
+	callback := func(ep Episode) {
+
+		if ep.Absolute == absolute && ep.Season > 0 {
+			episode = ep
+		}
+	}
+
+	err := tkn.getEpisodes(seriesID, callback)
+
+	if err == nil && episode.Absolute == 0 {
+		err = fmt.Errorf("episode %v not found on the TVDB", absolute)
+	}
+
+	return episode, err
+}
+
 func (tkn *TVDBToken) getEpisodes(seriesID int, callback func(Episode)) error {
 
 	var episodeSearchResponse struct {
 		Data []struct {
 			Season      uint   `json:"airedSeason"`
 			Episode     uint   `json:"airedEpisodeNumber"`
+			Absolute    uint   `json:"absoluteNumber"`
 			EpisodeName string `json:"episodeName"`
 			FirstAired  string `json:"firstAired"`
 		} `json:"data"`
@@ -185,7 +288,7 @@ func (tkn *TVDBToken) getEpisodes(seriesID int, callback func(Episode)) error {
 
 		url := fmt.Sprintf("%v?page=%v", baseURL, pageNum)
 
-		err := tkn.apiCall(url, &episodeSearchResponse)
+		err := tkn.apiCall(url, &episodeSearchResponse, EpisodesCacheTTL)
 
 		if err != nil {
 			return err
@@ -207,10 +310,11 @@ func (tkn *TVDBToken) getEpisodes(seriesID int, callback func(Episode)) error {
 			}
 
 			episode := Episode{
-				Season:  ep.Season,
-				Episode: ep.Episode,
-				Title:   ep.EpisodeName,
-				Aired:   aired,
+				Season:   ep.Season,
+				Episode:  ep.Episode,
+				Absolute: ep.Absolute,
+				Title:    ep.EpisodeName,
+				Aired:    aired,
 			}
 
 			callback(episode)
@@ -223,9 +327,14 @@ func (tkn *TVDBToken) getEpisodes(seriesID int, callback func(Episode)) error {
 	return nil
 }
 
-func (tkn *TVDBToken) apiCall(url string, v interface{}) error {
+func (tkn *TVDBToken) apiCall(url string, v interface{}, cacheTTL time.Duration) error {
 
-	httpClient := utils.HTTPClient{AuthToken: tkn.Token}
+	httpClient := utils.HTTPClient{
+		AuthToken: tkn.Token,
+		Category:  utils.MetadataRequests,
+		Context:   tkn.ctx,
+		CacheTTL:  cacheTTL,
+	}
 
 	return httpClient.GetJSON(url, &v)
 }
@@ -0,0 +1,203 @@
+package torrents
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DecodeBencode decodes a single bencoded value from the given data.
+// Integers are decoded as int64, byte strings as string, lists as []interface{}
+// and dictionaries as map[string]interface{}.
+func DecodeBencode(data []byte) (interface{}, error) {
+
+	decoder := bencodeDecoder{data: data}
+
+	value, err := decoder.decode()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.pos != len(data) {
+		return nil, fmt.Errorf("bencode: trailing data at offset %v", decoder.pos)
+	}
+
+	return value, nil
+}
+
+// maxBencodeDepth is the deepest nesting of lists and dictionaries that is decoded, which real .torrent files
+// never come close to, so that malicious data cannot exhaust the stack.
+const maxBencodeDepth = 64
+
+// bencodeDecoder decodes bencoded data, while also keeping track of where the "info" dictionary
+// of a .torrent file begins and ends, since its raw bytes are needed to compute the info hash.
+type bencodeDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+
+	infoStart int
+	infoEnd   int
+}
+
+func (d *bencodeDecoder) decode() (interface{}, error) {
+
+	if d.pos >= len(d.data) {
+		return nil, errors.New("bencode: unexpected end of data")
+	}
+
+	switch c := d.data[d.pos]; {
+
+	case c == 'i':
+		return d.decodeInt()
+
+	case c == 'l':
+		return d.decodeList()
+
+	case c == 'd':
+		return d.decodeDict()
+
+	case c >= '0' && c <= '9':
+		return d.decodeString()
+	}
+
+	return nil, fmt.Errorf("bencode: invalid character %q at offset %v", d.data[d.pos], d.pos)
+}
+
+func (d *bencodeDecoder) decodeInt() (int64, error) {
+
+	end := d.indexFrom('e')
+
+	if end < 0 {
+		return 0, fmt.Errorf("bencode: unterminated integer at offset %v", d.pos)
+	}
+
+	value, err := strconv.ParseInt(string(d.data[d.pos+1:end]), 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("bencode: invalid integer at offset %v", d.pos)
+	}
+
+	d.pos = end + 1
+
+	return value, nil
+}
+
+func (d *bencodeDecoder) decodeString() (string, error) {
+
+	colon := d.indexFrom(':')
+
+	if colon < 0 {
+		return "", fmt.Errorf("bencode: unterminated string length at offset %v", d.pos)
+	}
+
+	length, err := strconv.Atoi(string(d.data[d.pos:colon]))
+
+	// The remaining length is compared, since adding a huge length to the offset could overflow.
+	if err != nil || length < 0 || length > len(d.data)-colon-1 {
+		return "", fmt.Errorf("bencode: invalid string length at offset %v", d.pos)
+	}
+
+	value := string(d.data[colon+1 : colon+1+length])
+
+	d.pos = colon + 1 + length
+
+	return value, nil
+}
+
+func (d *bencodeDecoder) decodeList() ([]interface{}, error) {
+
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+
+	list := []interface{}{}
+
+	for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+
+		value, err := d.decode()
+
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, value)
+	}
+
+	if d.pos >= len(d.data) {
+		return nil, errors.New("bencode: unterminated list")
+	}
+
+	d.pos++
+	d.depth--
+
+	return list, nil
+}
+
+func (d *bencodeDecoder) decodeDict() (map[string]interface{}, error) {
+
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+
+	dict := map[string]interface{}{}
+
+	for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+
+		key, err := d.decodeString()
+
+		if err != nil {
+			return nil, err
+		}
+
+		start := d.pos
+
+		value, err := d.decode()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if d.depth == 1 && key == "info" {
+			d.infoStart = start
+			d.infoEnd = d.pos
+		}
+
+		dict[key] = value
+	}
+
+	if d.pos >= len(d.data) {
+		return nil, errors.New("bencode: unterminated dictionary")
+	}
+
+	d.pos++
+	d.depth--
+
+	return dict, nil
+}
+
+// enter moves past the opening character of a list or a dictionary, returning an error if it is nested too deep.
+func (d *bencodeDecoder) enter() error {
+
+	if d.depth >= maxBencodeDepth {
+		return fmt.Errorf("bencode: nesting deeper than %v at offset %v", maxBencodeDepth, d.pos)
+	}
+
+	d.pos++
+	d.depth++
+
+	return nil
+}
+
+func (d *bencodeDecoder) indexFrom(c byte) int {
+
+	for i := d.pos; i < len(d.data); i++ {
+
+		if d.data[i] == c {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,81 @@
+package torrents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBencode(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out interface{}
+	}{
+		{"i42e", int64(42)},
+		{"i-3e", int64(-3)},
+		{"4:spam", "spam"},
+		{"0:", ""},
+		{"l4:spami42ee", []interface{}{"spam", int64(42)}},
+		{"le", []interface{}{}},
+		{"d3:cow3:moo4:spaml1:a1:bee", map[string]interface{}{"cow": "moo", "spam": []interface{}{"a", "b"}}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			v, err := DecodeBencode([]byte(tt.in))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, tt.out) {
+				t.Errorf("got %v, want %v", v, tt.out)
+			}
+		})
+	}
+}
+
+func TestDecodeBencodeErrors(t *testing.T) {
+
+	table := []string{
+		"", "i42", "ixe", "5:spam", "l4:spam", "d3:cow", "di1e3:mooe", "x", "i1ei2e",
+		"9223372036854775807:", "d4:info9223372036854775807:e", "l9223372036854775800:xe",
+	}
+
+	for _, in := range table {
+		t.Run(in, func(t *testing.T) {
+
+			if v, err := DecodeBencode([]byte(in)); err == nil {
+				t.Errorf("expected error, got %v", v)
+			}
+		})
+	}
+}
+
+func TestDecodeBencodeDepth(t *testing.T) {
+
+	table := []struct {
+		depth int
+		err   bool
+	}{
+		{1, false},
+		{maxBencodeDepth, false},
+		{maxBencodeDepth + 1, true},
+		{1000000, true},
+	}
+
+	for _, tt := range table {
+
+		lists := strings.Repeat("l", tt.depth) + strings.Repeat("e", tt.depth)
+		dicts := strings.Repeat("d1:a", tt.depth) + "i1e" + strings.Repeat("e", tt.depth)
+
+		for _, in := range []string{lists, dicts} {
+
+			if _, err := DecodeBencode([]byte(in)); (err != nil) != tt.err {
+				t.Errorf("depth %v: got error %v, want error %v", tt.depth, err, tt.err)
+			}
+		}
+	}
+}
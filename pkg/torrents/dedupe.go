@@ -0,0 +1,111 @@
+package torrents
+
+// InfoHash returns the info hash in the torrent's magnet link, or an empty string if it has none.
+func (t Torrent) InfoHash() string {
+
+	magnet, err := ParseMagnet(t.Magnet)
+
+	if err != nil {
+		return ""
+	}
+
+	if magnet.InfoHash != "" {
+		return magnet.InfoHash
+	}
+
+	return magnet.MultiHash
+}
+
+// DeduplicateTorrents merges the torrents in the list that share the same info hash, such as the same
+// torrent returned by multiple mirrors or sources. Torrents without an info hash are considered duplicates
+// if their titles are identical. The order of the list is preserved, with each torrent taking
+// the position of its first occurrence.
+func DeduplicateTorrents(torrents []Torrent) []Torrent {
+
+	var deduplicated []Torrent
+
+	index := map[string]int{}
+
+	for _, torrent := range torrents {
+
+		key := torrent.InfoHash()
+
+		if key == "" {
+			key = "title:" + torrent.Title
+		}
+
+		if i, exists := index[key]; exists {
+
+			deduplicated[i] = deduplicated[i].merge(torrent)
+
+		} else {
+
+			index[key] = len(deduplicated)
+			deduplicated = append(deduplicated, torrent)
+		}
+	}
+
+	return deduplicated
+}
+
+// merge combines two copies of the same torrent, keeping the highest peer counts, since the copy with
+// the most peers is usually the most recently updated one, and filling in any metadata that this copy lacks.
+func (t Torrent) merge(other Torrent) Torrent {
+
+	if other.Seeders > t.Seeders {
+		t.Seeders = other.Seeders
+	}
+	if other.Leeches > t.Leeches {
+		t.Leeches = other.Leeches
+	}
+	t.UnknownPeers = t.UnknownPeers && other.UnknownPeers
+
+	// Some pages truncate long titles.
+	if len(other.Title) > len(t.Title) {
+		t.Title = other.Title
+	}
+
+	t.VerifiedUploader = t.VerifiedUploader || other.VerifiedUploader
+
+	if t.Size == 0 {
+		t.Size = other.Size
+	}
+	if t.VideoQuality == Default {
+		t.VideoQuality = other.VideoQuality
+	}
+	if t.VideoRelease == "" {
+		t.VideoRelease = other.VideoRelease
+	}
+	if t.MirrorURL == "" && t.TorrentURL == "" {
+		t.MirrorURL = other.MirrorURL
+		t.TorrentURL = other.TorrentURL
+	}
+	if t.UploadTime.IsZero() || (!other.UploadTime.IsZero() && other.UploadTime.Before(t.UploadTime)) {
+		t.UploadTime = other.UploadTime
+	}
+	if t.Uploader == "" {
+		t.Uploader = other.Uploader
+	}
+	if t.IMDbID == "" {
+		t.IMDbID = other.IMDbID
+	}
+
+	// Combine the trackers of both magnet links.
+	magnet, err := ParseMagnet(t.Magnet)
+	otherMagnet, otherErr := ParseMagnet(other.Magnet)
+
+	if err != nil && otherErr == nil {
+
+		t.Magnet = other.Magnet
+
+	} else if err == nil && otherErr == nil {
+
+		merged := magnet.WithTrackers(otherMagnet.Trackers...)
+
+		if len(merged.Trackers) > len(magnet.Trackers) {
+			t.Magnet = merged.String()
+		}
+	}
+
+	return t
+}
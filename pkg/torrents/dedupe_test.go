@@ -0,0 +1,128 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInfoHash(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out string
+	}{
+		{"magnet:?xt=urn:btih:BEE75372B98077BFD4DE8EF03EB33E9289BE5CD8&dn=test", "bee75372b98077bfd4de8ef03eb33e9289be5cd8"},
+		{"magnet:?xt=urn:btih:X3TVG4VZQB337VG6R3YD5MZ6SKE34XGY", "bee75372b98077bfd4de8ef03eb33e9289be5cd8"},
+		{"magnet:?xt=urn:btmh:1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e", "1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e"},
+		{"https://example.com/torrent/1", ""},
+		{"", ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			torrent := Torrent{Magnet: tt.in}
+
+			if s := torrent.InfoHash(); s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
+
+func TestDeduplicateTorrents(t *testing.T) {
+
+	hashA := "bee75372b98077bfd4de8ef03eb33e9289be5cd8"
+	hashB := "b540e8e33d6b92d234b701e0a4bd58188d9079ce"
+
+	earlier := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	in := []Torrent{
+		{
+			Title:    "Some.Movie.2019.1080p",
+			Seeders:  10,
+			Leeches:  50,
+			Magnet:   "magnet:?xt=urn:btih:" + hashA + "&tr=udp%3A%2F%2Fone",
+			Uploader: "uploader",
+		},
+		{
+			Title:   "Unrelated Movie",
+			Seeders: 5,
+			Magnet:  "magnet:?xt=urn:btih:" + hashB,
+		},
+		{
+			Title:            "Some.Movie.2019.1080p.WEB-DL.x264",
+			Seeders:          40,
+			Leeches:          20,
+			Size:             1500000,
+			VerifiedUploader: true,
+			UploadTime:       later,
+			Magnet:           "magnet:?xt=urn:btih:X3TVG4VZQB337VG6R3YD5MZ6SKE34XGY&tr=udp%3A%2F%2Ftwo",
+		},
+		{
+			Title:      "Some.Movie.2019.1080p",
+			Seeders:    3,
+			UploadTime: earlier,
+			Magnet:     "magnet:?xt=urn:btih:" + hashA + "&tr=udp%3A%2F%2Fone",
+		},
+		{Title: "No Hash", Seeders: 1, MirrorURL: "https://a.example.com"},
+		{Title: "No Hash", Seeders: 2, MirrorURL: "https://b.example.com"},
+		{Title: "No Hash Either", Seeders: 3},
+	}
+
+	out := DeduplicateTorrents(in)
+
+	var titles []string
+	for _, torrent := range out {
+		titles = append(titles, torrent.Title)
+	}
+
+	expectedTitles := []string{"Some.Movie.2019.1080p.WEB-DL.x264", "Unrelated Movie", "No Hash", "No Hash Either"}
+
+	if !reflect.DeepEqual(titles, expectedTitles) {
+		t.Fatalf("got %v, want %v", titles, expectedTitles)
+	}
+
+	merged := out[0]
+
+	if merged.Seeders != 40 || merged.Leeches != 50 {
+		t.Errorf("got %v/%v peers, want 40/50", merged.Seeders, merged.Leeches)
+	}
+	if merged.Size != 1500000 {
+		t.Errorf("got size %v, want %v", merged.Size, 1500000)
+	}
+	if !merged.VerifiedUploader {
+		t.Errorf("got unverified uploader, want verified")
+	}
+	if merged.Uploader != "uploader" {
+		t.Errorf("got uploader %v, want %v", merged.Uploader, "uploader")
+	}
+	if !merged.UploadTime.Equal(earlier) {
+		t.Errorf("got upload time %v, want %v", merged.UploadTime, earlier)
+	}
+
+	magnet, err := ParseMagnet(merged.Magnet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTrackers := []string{"udp://one", "udp://two"}
+
+	if magnet.InfoHash != hashA || !reflect.DeepEqual(magnet.Trackers, expectedTrackers) {
+		t.Errorf("got %v, %v, want %v, %v", magnet.InfoHash, magnet.Trackers, hashA, expectedTrackers)
+	}
+
+	if out[2].Seeders != 2 || out[2].MirrorURL != "https://a.example.com" {
+		t.Errorf("got %v, %v, want %v, %v", out[2].Seeders, out[2].MirrorURL, 2, "https://a.example.com")
+	}
+}
+
+func TestDeduplicateTorrentsEmpty(t *testing.T) {
+
+	if out := DeduplicateTorrents(nil); len(out) != 0 {
+		t.Errorf("got %v, want empty", out)
+	}
+}
@@ -0,0 +1,185 @@
+package torrents
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+const defaultEZTVURL string = "https://eztvx.to"
+
+const eztvTimeout = 10 * time.Second
+
+// eztvPageLimit is the number of torrents requested per page, which is also the maximum the API allows.
+const eztvPageLimit = 100
+
+// eztvMaxPages limits how far back the torrents of a series are searched, since they are listed newest first.
+const eztvMaxPages = 10
+
+// EZTVConfig holds the configuration of the EZTV episode source.
+type EZTVConfig struct {
+	URL string `toml:"url"`
+}
+
+// EZTVAPIResponse represents the response returned by the get-torrents endpoint of the EZTV API.
+type EZTVAPIResponse struct {
+	IMDbID        string                   `json:"imdb_id"`
+	TorrentsCount int                      `json:"torrents_count"`
+	Page          int                      `json:"page"`
+	Torrents      []EZTVAPIResponseTorrent `json:"torrents"`
+}
+
+// EZTVAPIResponseTorrent represents a single torrent, as it is returned by the EZTV API.
+type EZTVAPIResponseTorrent struct {
+	Hash             string `json:"hash"`
+	EpisodeURL       string `json:"episode_url"`
+	MagnetURL        string `json:"magnet_url"`
+	Title            string `json:"title"`
+	IMDbID           string `json:"imdb_id"`
+	Season           string `json:"season"`
+	Episode          string `json:"episode"`
+	Seeds            int    `json:"seeds"`
+	Peers            int    `json:"peers"`
+	DateReleasedUnix int64  `json:"date_released_unix"`
+	SizeBytes        string `json:"size_bytes"`
+}
+
+// eztvSource searches an EZTV-style JSON API, which lists the torrents of a series by its IMDb ID.
+type eztvSource struct {
+	url string
+	ctx context.Context
+}
+
+func init() {
+	RegisterSource("eztv", newEZTVSource)
+}
+
+func newEZTVSource(filters SearchFilters) (Source, error) {
+
+	eztvURL := filters.Sources.EZTV.URL
+
+	if eztvURL == "" {
+		eztvURL = defaultEZTVURL
+	}
+
+	return &eztvSource{eztvURL, filters.Context}, nil
+}
+
+func (s *eztvSource) Name() string {
+	return "eztv"
+}
+
+// Search yields no results, since the EZTV API can only look up torrents by the IMDb ID of a series.
+func (s *eztvSource) Search(query string) ([]Torrent, error) {
+
+	return nil, nil
+}
+
+func (s *eztvSource) SearchEpisode(imdbID string, episode EpisodeQuery) ([]Torrent, error) {
+
+	client := utils.HTTPClient{
+		Timeout:  eztvTimeout,
+		Category: utils.TorrentRequests,
+		Context:  s.ctx,
+	}
+
+	var trnts []Torrent
+
+	for page := 1; page <= eztvMaxPages; page++ {
+
+		searchURL, err := s.SearchURL(imdbID, page)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var response EZTVAPIResponse
+
+		if err = client.GetJSON(searchURL, &response); err != nil {
+			return nil, err
+		}
+
+		trnts = append(trnts, response.GetTorrents(episode)...)
+
+		if len(response.Torrents) < eztvPageLimit {
+			break
+		}
+	}
+
+	return trnts, nil
+}
+
+// SearchURL returns the URL of the API call that lists the given page of torrents for the series with the given IMDb ID.
+func (s *eztvSource) SearchURL(imdbID string, page int) (string, error) {
+
+	searchURL, err := url.Parse(s.url)
+
+	if err != nil {
+		return "", err
+	}
+
+	searchURL.Path = "/api/get-torrents"
+
+	queryBuilder := searchURL.Query()
+	queryBuilder.Set("imdb_id", strings.TrimLeft(imdbID, "t"))
+	queryBuilder.Set("limit", strconv.Itoa(eztvPageLimit))
+	queryBuilder.Set("page", strconv.Itoa(page))
+	searchURL.RawQuery = queryBuilder.Encode()
+
+	return searchURL.String(), nil
+}
+
+// GetTorrents converts the response from the EZTV API into a list of torrents,
+// only keeping the ones that belong to the given episode.
+func (response EZTVAPIResponse) GetTorrents(episode EpisodeQuery) []Torrent {
+
+	var trnts []Torrent
+
+	for _, obj := range response.Torrents {
+
+		season, _ := strconv.ParseUint(obj.Season, 10, 32)
+		episodeNum, _ := strconv.ParseUint(obj.Episode, 10, 32)
+
+		if !episode.Matches(uint(season), uint(episodeNum)) {
+			continue
+		}
+
+		sizeBytes, _ := strconv.ParseInt(obj.SizeBytes, 10, 64)
+
+		magnet := obj.MagnetURL
+		if magnet == "" {
+			magnet = NewMagnet(obj.Hash, obj.Title).String()
+		}
+
+		torrent := Torrent{
+			Title:        obj.Title,
+			Size:         sizeBytes / 1000,
+			Seeders:      obj.Seeds,
+			Leeches:      obj.Peers,
+			VideoQuality: extractVideoQuality(obj.Title),
+			VideoRelease: ExtractVideoRelease(obj.Title),
+			Magnet:       magnet,
+			UploadTime:   time.Unix(obj.DateReleasedUnix, 0),
+			Uploader:     "EZTV",
+		}
+
+		// The IMDb ID is left empty when it is missing or invalid.
+		if imdbID, err := strconv.Atoi(obj.IMDbID); err == nil && imdbID > 0 {
+			torrent.IMDbID = fmt.Sprintf("tt%07d", imdbID)
+		}
+
+		if episodeURL, err := url.Parse(obj.EpisodeURL); err == nil && episodeURL.Host != "" {
+			torrent.MirrorURL = fmt.Sprintf("%v://%v", episodeURL.Scheme, episodeURL.Host)
+			torrent.TorrentURL = episodeURL.Path
+		}
+
+		trnts = append(trnts, torrent)
+	}
+
+	return trnts
+}
@@ -0,0 +1,149 @@
+package torrents
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func eztvTestServer() *httptest.Server {
+
+	return sampleServer(sampleRoute{
+		path:   "/api/get-torrents",
+		sample: "eztv.json",
+		match: func(query url.Values) bool {
+			return query.Get("imdb_id") == "3230854" && query.Get("page") == "1"
+		},
+		empty: `{"torrents_count":0,"page":1,"torrents":[]}`,
+	})
+}
+
+func TestEZTVSearchURL(t *testing.T) {
+
+	source := eztvSource{url: "https://eztv.example.org"}
+
+	s, err := source.SearchURL("tt3230854", 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "https://eztv.example.org/api/get-torrents?imdb_id=3230854&limit=100&page=2"
+
+	if s != want {
+		t.Errorf("got %v, want %v", s, want)
+	}
+}
+
+func TestEZTVSearchEpisode(t *testing.T) {
+
+	server := eztvTestServer()
+	defer server.Close()
+
+	source, err := NewSource("eztv", SearchFilters{Sources: SourceConfig{EZTV: EZTVConfig{URL: server.URL}}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := []struct {
+		episode EpisodeQuery
+		count   int
+	}{
+		{EpisodeQuery{3, 7}, 2},
+		{EpisodeQuery{3, 8}, 1},
+		{EpisodeQuery{3, 0}, 3},
+		{EpisodeQuery{2, 13}, 1},
+		{EpisodeQuery{4, 1}, 0},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.episode.SearchTerm(), func(t *testing.T) {
+
+			torrents, err := source.(EpisodeSource).SearchEpisode("tt3230854", tt.episode)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(torrents) != tt.count {
+				t.Errorf("got %v torrents, want %v", len(torrents), tt.count)
+			}
+		})
+	}
+
+	torrents, _ := source.(EpisodeSource).SearchEpisode("tt3230854", EpisodeQuery{3, 7})
+
+	tor := torrents[1]
+
+	if tor.Title != "The Expanse S03E07 720p HDTV x264-AVS EZTV" || tor.Size != 1073741 || tor.Seeders != 60 || tor.Leeches != 8 ||
+		tor.VideoQuality != Medium || tor.IMDbID != "tt3230854" || !tor.UploadTime.Equal(time.Unix(1527732000, 0)) {
+
+		t.Errorf("got %v", tor)
+	}
+
+	if !strings.Contains(tor.Magnet, "xt=urn:btih:2d4f6a8c0e2b4d6f8a0c2e4b6d8f0a2c4e6b8d0f") {
+		t.Errorf("got magnet %v", tor.Magnet)
+	}
+
+	if tor.FullURL() != "https://eztvx.to/ep/1292170/the-expanse-s03e07-720p-hdtv-x264-avs/" {
+		t.Errorf("got url %v", tor.FullURL())
+	}
+}
+
+func TestEZTVTorrentIMDbID(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out string
+	}{
+		{"3230854", "tt3230854"},
+		{"944947", "tt0944947"},
+		{"", ""},
+		{"0", ""},
+		{"tt3230854", ""},
+		{"unknown", ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			response := EZTVAPIResponse{Torrents: []EZTVAPIResponseTorrent{{Title: "The Expanse S03E07", IMDbID: tt.in}}}
+
+			if torrents := response.GetTorrents(EpisodeQuery{}); len(torrents) != 1 || torrents[0].IMDbID != tt.out {
+				t.Errorf("got %v, want %v", torrents, tt.out)
+			}
+		})
+	}
+}
+
+func TestEZTVSearchByIMDbID(t *testing.T) {
+
+	server := eztvTestServer()
+	defer server.Close()
+
+	filters := SearchFilters{
+		Sources:     SourceConfig{Enabled: []string{"eztv"}, EZTV: EZTVConfig{URL: server.URL}},
+		IMDbID:      "tt3230854",
+		Episode:     &EpisodeQuery{3, 7},
+		SearchTerms: []string{"the expanse", "S03E07"},
+	}
+
+	torrents, err := filters.SearchVideoTorrents("the expanse S03E07")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torrents) != 2 {
+		t.Errorf("got %v, want both releases of the episode", torrents)
+	}
+
+	filters.IMDbID = ""
+
+	if torrents, _ := filters.SearchTorrents("the expanse"); len(torrents) != 0 {
+		t.Errorf("expected no results from a plain search, got %v", torrents)
+	}
+}
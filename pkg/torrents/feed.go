@@ -0,0 +1,303 @@
+package torrents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/haath/gobytes"
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+const feedTimeout = 10 * time.Second
+
+// FeedDocument represents either an RSS 2.0 or an Atom feed of torrents.
+// Elements from the torrent, nyaa and torznab namespaces are matched by their local name.
+type FeedDocument struct {
+	Channel struct {
+		Items []FeedItem `xml:"item"`
+	} `xml:"channel"`
+	Entries []FeedEntry `xml:"entry"`
+}
+
+// FeedItem represents a single item of an RSS feed.
+type FeedItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+	Enclosure   struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+	MagnetURI     string `xml:"magnetURI"`
+	InfoHash      string `xml:"infoHash"`
+	ContentLength int64  `xml:"contentLength"`
+	Size          string `xml:"size"`
+	Seeders       string `xml:"seeders"`
+	Leechers      string `xml:"leechers"`
+	Trusted       string `xml:"trusted"`
+}
+
+// FeedEntry represents a single entry of an Atom feed.
+type FeedEntry struct {
+	Title     string `xml:"title"`
+	ID        string `xml:"id"`
+	Updated   string `xml:"updated"`
+	Published string `xml:"published"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Links     []struct {
+		Href   string `xml:"href,attr"`
+		Rel    string `xml:"rel,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"link"`
+}
+
+// feedSource reads the configured RSS and Atom feeds, and matches their items against the search locally.
+type feedSource struct {
+	feeds   []string
+	filters SearchFilters
+}
+
+// feedURLSource wraps a single feed, so that the feeds can be read concurrently like any other source.
+type feedURLSource struct {
+	url string
+	ctx context.Context
+}
+
+func init() {
+	RegisterSource("feeds", newFeedSource)
+}
+
+func newFeedSource(filters SearchFilters) (Source, error) {
+
+	if len(filters.Sources.Feeds) == 0 {
+		return nil, errors.New("the feeds source is enabled, but no feed URLs are configured")
+	}
+
+	return &feedSource{filters.Sources.Feeds, filters}, nil
+}
+
+func (s *feedSource) Name() string {
+	return "feeds"
+}
+
+// Search reads all of the feeds, returning only the items whose titles contain every word of the query
+// and that comply with the filters of the search.
+func (s *feedSource) Search(query string) ([]Torrent, error) {
+
+	var sources []Source
+
+	for _, feedURL := range s.feeds {
+		sources = append(sources, feedURLSource{feedURL, s.filters.Context})
+	}
+
+	trnts, err := SearchSources(sources, query)
+
+	filters := s.filters
+	filters.SearchTerms = append(strings.Fields(utils.NormalizeQuery(query)), filters.SearchTerms...)
+
+	return filters.FilterTorrents(trnts), err
+}
+
+func (s feedURLSource) Name() string {
+	return s.url
+}
+
+// Search returns every item in the feed, since feeds cannot be queried.
+func (s feedURLSource) Search(query string) ([]Torrent, error) {
+
+	client := utils.HTTPClient{
+		Timeout:  feedTimeout,
+		Category: utils.TorrentRequests,
+		Context:  s.ctx,
+	}
+
+	var feed FeedDocument
+
+	if err := client.GetXML(s.url, &feed); err != nil {
+		return nil, err
+	}
+
+	trnts := feed.GetTorrents()
+
+	// The host of the feed stands in for the uploader, so that feeds can be whitelisted or blacklisted.
+	if feedURL, err := url.Parse(s.url); err == nil {
+
+		for i := range trnts {
+			trnts[i].Uploader = feedURL.Host
+		}
+	}
+
+	return trnts, nil
+}
+
+// GetTorrents converts the items of an RSS feed or the entries of an Atom feed into a list of torrents.
+func (feed FeedDocument) GetTorrents() []Torrent {
+
+	var trnts []Torrent
+
+	for _, item := range feed.Channel.Items {
+		trnts = append(trnts, item.GetTorrent())
+	}
+
+	for _, entry := range feed.Entries {
+		trnts = append(trnts, entry.GetTorrent())
+	}
+
+	return trnts
+}
+
+// GetTorrent converts a single RSS item into a torrent.
+func (item FeedItem) GetTorrent() Torrent {
+
+	sizeBytes := item.ContentLength
+	if sizeBytes == 0 {
+		sizeBytes = item.Enclosure.Length
+	}
+
+	size := sizeBytes / 1000
+	if size == 0 {
+		size = extractFeedSize(item.Size)
+	}
+	if size == 0 {
+		size = extractFeedSize(item.Description)
+	}
+
+	magnet := item.MagnetURI
+	for _, link := range []string{item.Link, item.Enclosure.URL, item.GUID} {
+		if magnet == "" && strings.HasPrefix(link, "magnet:") {
+			magnet = link
+		}
+	}
+	if magnet == "" && item.InfoHash != "" {
+		magnet = NewMagnet(item.InfoHash, item.Title).String()
+	}
+
+	seeders, _ := strconv.Atoi(item.Seeders)
+	leeches, _ := strconv.Atoi(item.Leechers)
+
+	pageURL := item.Link
+	if pageURL == "" || strings.HasPrefix(pageURL, "magnet:") {
+		pageURL = item.GUID
+	}
+
+	torrent := Torrent{
+		Title:            item.Title,
+		Size:             size,
+		Seeders:          seeders,
+		Leeches:          leeches,
+		UnknownPeers:     item.Seeders == "", // Most feeds do not report peers.
+		VerifiedUploader: strings.EqualFold(item.Trusted, "yes"),
+		VideoQuality:     extractVideoQuality(item.Title),
+		VideoRelease:     ExtractVideoRelease(item.Title),
+		Magnet:           magnet,
+		UploadTime:       parseFeedTime(item.PubDate),
+	}
+
+	torrent.MirrorURL, torrent.TorrentURL = splitTorrentURL(pageURL)
+
+	return torrent
+}
+
+// GetTorrent converts a single Atom entry into a torrent.
+func (entry FeedEntry) GetTorrent() Torrent {
+
+	item := FeedItem{
+		Title:       entry.Title,
+		GUID:        entry.ID,
+		PubDate:     entry.Published,
+		Description: entry.Summary,
+	}
+
+	if item.PubDate == "" {
+		item.PubDate = entry.Updated
+	}
+	if item.Description == "" {
+		item.Description = entry.Content
+	}
+
+	for _, link := range entry.Links {
+
+		if link.Rel == "enclosure" {
+
+			item.Enclosure.URL = link.Href
+			item.Enclosure.Length = link.Length
+
+		} else if strings.HasPrefix(link.Href, "magnet:") {
+
+			item.MagnetURI = link.Href
+
+		} else if item.Link == "" {
+
+			item.Link = link.Href
+		}
+	}
+
+	return item.GetTorrent()
+}
+
+// extractFeedSize looks for a size such as "1.4 GiB" in the given text, and returns it in kilobytes.
+// Decimal units are treated as binary ones, since that is what most trackers mean by them.
+func extractFeedSize(text string) int64 {
+
+	r, _ := regexp.Compile(`(?i)(\d+(?:\.\d+)?)\s*([KMGT])i?B\b`)
+	m := r.FindStringSubmatch(text)
+
+	if len(m) == 0 {
+		return 0
+	}
+
+	value, _ := strconv.ParseFloat(m[1], 64)
+
+	units := map[string]float64{
+		"K": gobytes.KiB.KBytes(),
+		"M": gobytes.MiB.KBytes(),
+		"G": gobytes.GiB.KBytes(),
+		"T": gobytes.GiB.KBytes() * 1024,
+	}
+
+	return int64(math.Round(value * units[strings.ToUpper(m[2])]))
+}
+
+// parseFeedTime parses the publication date of an RSS item or an Atom entry.
+func parseFeedTime(date string) time.Time {
+
+	layouts := []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700"}
+
+	for _, layout := range layouts {
+
+		if t, err := time.Parse(layout, strings.TrimSpace(date)); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// splitTorrentURL splits the URL of a torrent's page into the MirrorURL and TorrentURL fields of a torrent.
+func splitTorrentURL(pageURL string) (mirrorURL string, torrentURL string) {
+
+	parsedURL, err := url.Parse(pageURL)
+
+	if err != nil || parsedURL.Host == "" {
+		return
+	}
+
+	mirrorURL = fmt.Sprintf("%v://%v", parsedURL.Scheme, parsedURL.Host)
+	torrentURL = parsedURL.Path
+
+	if parsedURL.RawQuery != "" {
+		torrentURL += fmt.Sprintf("?%s", parsedURL.RawQuery)
+	}
+
+	return
+}
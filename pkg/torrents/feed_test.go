@@ -0,0 +1,178 @@
+package torrents
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func feedTestServer() *httptest.Server {
+
+	return sampleServer(
+		sampleRoute{path: "/rss", sample: "feed.xml"},
+		sampleRoute{path: "/atom", sample: "feed_atom.xml"},
+	)
+}
+
+func TestExtractFeedSize(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out int64
+	}{
+		{"Size: 2.5 GiB", 2684355},
+		{"1.2 GB", 1288490},
+		{"<b>Size</b> 850 MiB, 12 files", 891290},
+		{"512KiB", 524},
+		{"No size here", 0},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			s := extractFeedSize(tt.in)
+
+			if s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseFeedTime(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out time.Time
+	}{
+		{"Thu, 31 May 2018 03:12:45 +0000", time.Date(2018, time.May, 31, 3, 12, 45, 0, time.UTC)},
+		{"Thu, 31 May 2018 03:12:45 GMT", time.Date(2018, time.May, 31, 3, 12, 45, 0, time.UTC)},
+		{"2018-05-31T05:00:00Z", time.Date(2018, time.May, 31, 5, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Time{}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			tm := parseFeedTime(tt.in)
+
+			if !tm.Equal(tt.out) {
+				t.Errorf("got %v, want %v", tm, tt.out)
+			}
+		})
+	}
+}
+
+func TestFeedURLSourceSearch(t *testing.T) {
+
+	server := feedTestServer()
+	defer server.Close()
+
+	torrents, err := feedURLSource{url: server.URL + "/rss"}.Search("")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torrents) != 3 {
+		t.Fatalf("got %v torrents, want 3", len(torrents))
+	}
+
+	tor := torrents[0]
+
+	if tor.Size != 1073741 || tor.Seeders != 0 || !tor.UnknownPeers || tor.VideoQuality != Medium ||
+		!tor.UploadTime.Equal(time.Date(2018, time.May, 31, 3, 12, 45, 0, time.UTC)) ||
+		!strings.HasPrefix(tor.Magnet, "magnet:?xt=urn:btih:8cd8a1a2e3b7c9f1d4a1b6e2a3c4d5e6f7a8b9c0") ||
+		tor.FullURL() != "https://feeds.example.org/torrent/1001" || !strings.HasPrefix(server.URL, "http://"+tor.Uploader) {
+
+		t.Errorf("got %v", tor)
+	}
+
+	// Unknown seeders are not rejected by the minimum, while known ones are.
+	filters := SearchFilters{MinSeeders: 50}
+
+	if !filters.IsOk(&torrents[0]) || filters.IsOk(&torrents[1]) {
+		t.Errorf("expected only the torrent with unknown seeders to pass %v minimum seeders", filters.MinSeeders)
+	}
+
+	tor = torrents[1]
+
+	if tor.Size != 2684355 || tor.Seeders != 45 || tor.Leeches != 3 || tor.UnknownPeers || tor.VideoQuality != High ||
+		!strings.Contains(tor.Magnet, "xt=urn:btih:0123456789abcdef0123456789abcdef01234567") {
+
+		t.Errorf("got %v", tor)
+	}
+
+	tor = torrents[2]
+
+	if tor.Size != 891290 || !strings.HasPrefix(tor.Magnet, "magnet:?xt=urn:btih:fedcba98") ||
+		tor.FullURL() != "https://feeds.example.org/torrent/1003" {
+
+		t.Errorf("got %v", tor)
+	}
+
+	torrents, err = feedURLSource{url: server.URL + "/atom"}.Search("")
+
+	if err != nil || len(torrents) != 1 {
+		t.Fatalf("got %v, %v", torrents, err)
+	}
+
+	tor = torrents[0]
+
+	if tor.Size != 8697309 || tor.VideoQuality != UHD || tor.FullURL() != "https://atom.example.org/t/77" ||
+		!strings.HasPrefix(tor.Magnet, "magnet:?xt=urn:btih:aaaabbbb") ||
+		!tor.UploadTime.Equal(time.Date(2018, time.May, 31, 5, 0, 0, 0, time.UTC)) {
+
+		t.Errorf("got %v", tor)
+	}
+}
+
+func TestFeedSource(t *testing.T) {
+
+	server := feedTestServer()
+	defer server.Close()
+
+	filters := SearchFilters{
+		Sources: SourceConfig{
+			Enabled: []string{"feeds"},
+			Feeds:   []string{server.URL + "/rss", server.URL + "/atom"},
+		},
+	}
+
+	table := []struct {
+		query       string
+		searchTerms []string
+		minQuality  VideoQuality
+		count       int
+	}{
+		{"the expanse s03e07", nil, "", 3},
+		{"westworld", nil, "", 1},
+		{"the expanse", []string{"the expanse", "S03E07"}, High, 2},
+		{"the expanse s03e08", nil, "", 0},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.query, func(t *testing.T) {
+
+			filters.SearchTerms = tt.searchTerms
+			filters.MinQuality = tt.minQuality
+
+			torrents, err := filters.SearchTorrents(tt.query)
+
+			if err != nil {
+				t.Error(err)
+			}
+
+			if len(torrents) != tt.count {
+				t.Errorf("got %v torrents, want %v", len(torrents), tt.count)
+			}
+		})
+	}
+
+	filters.Sources.Feeds = nil
+
+	if _, err := filters.GetSources(); err == nil {
+		t.Errorf("expected error for feeds source without URLs")
+	}
+}
@@ -0,0 +1,612 @@
+package torrents
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gitlab.com/haath/gobytes"
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+// FilterExpression is a boolean expression over the fields of a torrent, such as
+//
+//	1080p and (x265 or size < 2GB) and not uploader:foo
+//
+// Conditions are combined with and, or, not and parentheses. A condition is either a field, an operator and a value,
+// or a single word, which matches a quality, codec, HDR format, release type, the word trusted, or otherwise part of the title.
+type FilterExpression struct {
+	source string
+	root   filterPredicate
+}
+
+type filterPredicate func(t *Torrent, info *ReleaseInfo) bool
+
+type filterTokenKind int
+
+const (
+	filterWord filterTokenKind = iota
+	filterString
+	filterRegex
+	filterOperator
+	filterNot
+	filterOpen
+	filterClose
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+	pos   int
+}
+
+// ParseFilterExpression parses the given filter expression.
+func ParseFilterExpression(expression string) (*FilterExpression, error) {
+
+	tokens, err := tokenizeFilterExpression(expression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parser := filterParser{tokens: tokens}
+
+	root, err := parser.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos < len(tokens) {
+		return nil, parser.errorf("unexpected %q", tokens[parser.pos].value)
+	}
+
+	return &FilterExpression{source: expression, root: root}, nil
+}
+
+// Matches returns true if the given torrent satisfies the expression.
+func (e *FilterExpression) Matches(torrent *Torrent) bool {
+
+	info := torrent.ReleaseInfo()
+
+	return e.root(torrent, &info)
+}
+
+// String returns the expression as it was given.
+func (e *FilterExpression) String() string {
+	return e.source
+}
+
+// CombineFilterExpressions combines the given expressions so that a torrent needs to match all of them,
+// skipping any that are empty.
+func CombineFilterExpressions(expressions ...string) string {
+
+	var nonEmpty []string
+
+	for _, expr := range expressions {
+		if strings.TrimSpace(expr) != "" {
+			nonEmpty = append(nonEmpty, expr)
+		}
+	}
+
+	if len(nonEmpty) == 1 {
+		return nonEmpty[0]
+	}
+
+	for i := range nonEmpty {
+		nonEmpty[i] = "(" + nonEmpty[i] + ")"
+	}
+
+	return strings.Join(nonEmpty, " and ")
+}
+
+func tokenizeFilterExpression(expression string) ([]filterToken, error) {
+
+	var tokens []filterToken
+
+	runes := []rune(expression)
+
+	isSpecial := func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`()<>=!:"/`, r)
+	}
+
+	for i := 0; i < len(runes); {
+
+		r := runes[i]
+
+		switch {
+
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{filterOpen, "(", i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, filterToken{filterClose, ")", i})
+			i++
+
+		case r == '"' || r == '/':
+
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+
+			if end >= len(runes) {
+				return nil, fmt.Errorf("filter: unterminated %c at position %d", r, i+1)
+			}
+
+			kind := filterString
+			if r == '/' {
+				kind = filterRegex
+			}
+
+			tokens = append(tokens, filterToken{kind, string(runes[i+1 : end]), i})
+			i = end + 1
+
+		case strings.ContainsRune("<>=!:", r):
+
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != ':' && r != '=' {
+				op += "="
+			}
+
+			kind := filterOperator
+			if op == "!" {
+				kind = filterNot
+			}
+
+			tokens = append(tokens, filterToken{kind, op, i})
+			i += len(op)
+
+		default:
+
+			end := i
+			for end < len(runes) && !isSpecial(runes[end]) {
+				end++
+			}
+
+			tokens = append(tokens, filterToken{filterWord, string(runes[i:end]), i})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+
+	position := "end of expression"
+	if p.pos < len(p.tokens) {
+		position = fmt.Sprintf("position %d", p.tokens[p.pos].pos+1)
+	}
+
+	return fmt.Errorf("filter: "+format+" at %v", append(args, position)...)
+}
+
+func (p *filterParser) peek() *filterToken {
+
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+
+	return nil
+}
+
+func (p *filterParser) peekKeyword(keywords ...string) bool {
+
+	token := p.peek()
+
+	if token == nil || token.kind != filterWord {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if strings.EqualFold(token.value, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *filterParser) parseOr() (filterPredicate, error) {
+
+	left, err := p.parseAnd()
+
+	for err == nil && p.peekKeyword("or", "||") {
+
+		p.pos++
+
+		var right filterPredicate
+		right, err = p.parseAnd()
+
+		l, r := left, right
+		left = func(t *Torrent, info *ReleaseInfo) bool {
+			return l(t, info) || r(t, info)
+		}
+	}
+
+	return left, err
+}
+
+func (p *filterParser) parseAnd() (filterPredicate, error) {
+
+	left, err := p.parseNot()
+
+	for err == nil && p.peekKeyword("and", "&&") {
+
+		p.pos++
+
+		var right filterPredicate
+		right, err = p.parseNot()
+
+		l, r := left, right
+		left = func(t *Torrent, info *ReleaseInfo) bool {
+			return l(t, info) && r(t, info)
+		}
+	}
+
+	return left, err
+}
+
+func (p *filterParser) parseNot() (filterPredicate, error) {
+
+	if token := p.peek(); token != nil && (token.kind == filterNot || p.peekKeyword("not")) {
+
+		p.pos++
+
+		inner, err := p.parseNot()
+
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return !inner(t, info)
+		}, err
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterPredicate, error) {
+
+	token := p.peek()
+
+	if token == nil {
+		return nil, p.errorf("expected a condition")
+	}
+
+	switch token.kind {
+
+	case filterOpen:
+
+		p.pos++
+
+		inner, err := p.parseOr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if next := p.peek(); next == nil || next.kind != filterClose {
+			return nil, p.errorf("expected %q", ")")
+		}
+
+		p.pos++
+
+		return inner, nil
+
+	case filterWord:
+
+		p.pos++
+
+		if next := p.peek(); next != nil && next.kind == filterOperator {
+
+			p.pos++
+
+			value := p.peek()
+
+			if value == nil || (value.kind != filterWord && value.kind != filterString && value.kind != filterRegex) {
+				return nil, p.errorf("expected a value after %v%v", token.value, next.value)
+			}
+
+			p.pos++
+
+			predicate, err := newFieldPredicate(strings.ToLower(token.value), next.value, *value)
+
+			if err != nil {
+				p.pos--
+				return nil, p.errorf("%v", err)
+			}
+
+			return predicate, nil
+		}
+
+		return newTermPredicate(token.value), nil
+
+	case filterString:
+
+		p.pos++
+
+		return newTitlePredicate(token.value), nil
+
+	case filterRegex:
+
+		p.pos++
+
+		return newTitleRegexPredicate(token.value)
+	}
+
+	return nil, p.errorf("unexpected %q", token.value)
+}
+
+// newTermPredicate matches a single word against the fields it could refer to.
+func newTermPredicate(term string) filterPredicate {
+
+	if strings.EqualFold(term, "trusted") {
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return t.VerifiedUploader
+		}
+	}
+
+	if quality, ok := parseFilterQuality(term); ok {
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return t.VideoQuality == quality
+		}
+	}
+
+	if firstPatternValue(videoCodecPatterns, term) != "" {
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return info.matchesCodec(term)
+		}
+	}
+
+	if strings.EqualFold(term, "HDR") || strings.EqualFold(term, "SDR") || firstPatternValue(hdrPatterns, term) != "" {
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return info.matchesHDR(term)
+		}
+	}
+
+	if release := ParseVideoRelease(term); release != "" {
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return t.GetVideoRelease() == release
+		}
+	}
+
+	return newTitlePredicate(term)
+}
+
+func newTitlePredicate(text string) filterPredicate {
+
+	text = utils.NormalizeQuery(text)
+
+	return func(t *Torrent, info *ReleaseInfo) bool {
+		return strings.Contains(utils.NormalizeQuery(t.Title), text)
+	}
+}
+
+func newTitleRegexPredicate(pattern string) (filterPredicate, error) {
+
+	r, err := regexp.Compile(`(?i)` + pattern)
+
+	if err != nil {
+		return nil, fmt.Errorf("filter: invalid regular expression /%v/: %v", pattern, err)
+	}
+
+	return func(t *Torrent, info *ReleaseInfo) bool {
+		return r.MatchString(t.Title)
+	}, nil
+}
+
+// newFieldPredicate creates the condition for a field, an operator and a value.
+func newFieldPredicate(field string, op string, value filterToken) (filterPredicate, error) {
+
+	negate := func(predicate filterPredicate) filterPredicate {
+
+		if op != "!=" {
+			return predicate
+		}
+
+		return func(t *Torrent, info *ReleaseInfo) bool {
+			return !predicate(t, info)
+		}
+	}
+
+	equalityOnly := func() error {
+		if op != ":" && op != "=" && op != "!=" {
+			return fmt.Errorf("the %v field can not be compared with %v", field, op)
+		}
+		return nil
+	}
+
+	switch field {
+
+	case "title":
+
+		if err := equalityOnly(); err != nil {
+			return nil, err
+		}
+
+		if value.kind == filterRegex {
+			predicate, err := newTitleRegexPredicate(value.value)
+			return negate(predicate), err
+		}
+
+		return negate(newTitlePredicate(value.value)), nil
+
+	case "size":
+
+		var size gobytes.ByteSize
+
+		if err := size.UnmarshalText([]byte(value.value)); err != nil {
+			return nil, fmt.Errorf("invalid size %q", value.value)
+		}
+
+		return compareFilterValues(op, func(t *Torrent, info *ReleaseInfo) (float64, bool) {
+			return float64(t.Size), t.Size > 0
+		}, float64(size.KBytes()))
+
+	case "seeders", "leeches":
+
+		n, err := strconv.Atoi(value.value)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value.value)
+		}
+
+		return compareFilterValues(op, func(t *Torrent, info *ReleaseInfo) (float64, bool) {
+			if field == "seeders" {
+				return float64(t.Seeders), !t.UnknownPeers
+			}
+			return float64(t.Leeches), !t.UnknownPeers
+		}, float64(n))
+
+	case "quality":
+
+		quality, ok := parseFilterQuality(value.value)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid quality %q", value.value)
+		}
+
+		return compareFilterValues(op, func(t *Torrent, info *ReleaseInfo) (float64, bool) {
+			return float64(t.VideoQuality.numeric()), true
+		}, float64(quality.numeric()))
+
+	case "release":
+
+		release := ParseVideoRelease(value.value)
+
+		if release == "" {
+			return nil, fmt.Errorf("invalid release type %q", value.value)
+		}
+
+		return compareFilterValues(op, func(t *Torrent, info *ReleaseInfo) (float64, bool) {
+			return float64(t.GetVideoRelease().numeric()), t.GetVideoRelease() != ""
+		}, float64(release.numeric()))
+
+	case "age":
+
+		age, err := parseAge(value.value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return compareFilterValues(op, func(t *Torrent, info *ReleaseInfo) (float64, bool) {
+			return float64(time.Since(t.UploadTime)), !t.UploadTime.IsZero()
+		}, float64(age))
+
+	case "trusted":
+
+		trusted, err := strconv.ParseBool(value.value)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value.value)
+		}
+
+		if err := equalityOnly(); err != nil {
+			return nil, err
+		}
+
+		return negate(func(t *Torrent, info *ReleaseInfo) bool {
+			return t.VerifiedUploader == trusted
+		}), nil
+
+	case "uploader", "group":
+
+		if err := equalityOnly(); err != nil {
+			return nil, err
+		}
+
+		return negate(func(t *Torrent, info *ReleaseInfo) bool {
+			if field == "group" {
+				return strings.EqualFold(info.Group, value.value)
+			}
+			return strings.EqualFold(t.Uploader, value.value)
+		}), nil
+
+	case "codec", "hdr", "audio", "channels":
+
+		if err := equalityOnly(); err != nil {
+			return nil, err
+		}
+
+		return negate(func(t *Torrent, info *ReleaseInfo) bool {
+			switch field {
+			case "codec":
+				return info.matchesCodec(value.value)
+			case "hdr":
+				return info.matchesHDR(value.value)
+			case "audio":
+				return info.matchesAudio(value.value)
+			default:
+				return info.matchesChannels(value.value)
+			}
+		}), nil
+	}
+
+	return nil, fmt.Errorf("unknown field %q", field)
+}
+
+// compareFilterValues creates a condition that compares a value of the torrent with the given one.
+// Torrents for which the value is not known never satisfy the condition.
+func compareFilterValues(op string, get func(t *Torrent, info *ReleaseInfo) (float64, bool), value float64) (filterPredicate, error) {
+
+	var compare func(a, b float64) bool
+
+	switch op {
+	case ":", "=":
+		compare = func(a, b float64) bool { return a == b }
+	case "!=":
+		compare = func(a, b float64) bool { return a != b }
+	case "<":
+		compare = func(a, b float64) bool { return a < b }
+	case "<=":
+		compare = func(a, b float64) bool { return a <= b }
+	case ">":
+		compare = func(a, b float64) bool { return a > b }
+	case ">=":
+		compare = func(a, b float64) bool { return a >= b }
+	default:
+		return nil, fmt.Errorf("unknown operator %v", op)
+	}
+
+	return func(t *Torrent, info *ReleaseInfo) bool {
+		v, known := get(t, info)
+		return known && compare(v, value)
+	}, nil
+}
+
+func parseFilterQuality(value string) (VideoQuality, bool) {
+
+	if strings.EqualFold(value, string(Default)) {
+		return Default, true
+	}
+
+	if quality := extractVideoQuality(value); quality != Default {
+		return quality, true
+	}
+
+	return Default, false
+}
+
+// parseAge parses an age such as 12h, 7d, 2w or 1y.
+func parseAge(value string) (time.Duration, error) {
+
+	age, err := utils.ParseDuration(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q, expected a number followed by m, h, d, w or y", value)
+	}
+
+	return age, nil
+}
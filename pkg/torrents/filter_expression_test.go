@@ -0,0 +1,192 @@
+package torrents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterExpressionMatches(t *testing.T) {
+
+	x265 := Torrent{
+		Title:            "Some.Movie.2019.1080p.BluRay.DTS-HD.MA.5.1.x265-GROUP",
+		Size:             3000000,
+		Seeders:          120,
+		VideoQuality:     High,
+		Uploader:         "foo",
+		VerifiedUploader: true,
+		UploadTime:       time.Now().Add(-48 * time.Hour),
+	}
+	small := Torrent{
+		Title:        "Some.Movie.2019.1080p.WEB-DL.DDP5.1.x264-OTHER",
+		Size:         1500000,
+		Seeders:      8,
+		VideoQuality: High,
+		Uploader:     "bar",
+		UploadTime:   time.Now().Add(-30 * 24 * time.Hour),
+	}
+	cam := Torrent{
+		Title:        "Some Movie 2019 720p CAMRip",
+		Size:         900000,
+		Seeders:      500,
+		VideoQuality: Medium,
+		Uploader:     "baz",
+	}
+
+	table := []struct {
+		expr    string
+		torrent Torrent
+		out     bool
+	}{
+		{"1080p", x265, true},
+		{"1080p", cam, false},
+		{"x265", x265, true},
+		{"hevc", small, false},
+		{"bluray", x265, true},
+		{"trusted", small, false},
+		{"movie", cam, true},
+		{`"some movie 2019"`, cam, true},
+		{"1080p and (x265 or size < 2GB) and not uploader:foo", x265, false},
+		{"1080p and (x265 or size < 2GB) and not uploader:foo", small, true},
+		{"1080p and (x265 or size < 2GB) and not uploader:foo", cam, false},
+		{"1080p && !trusted", small, true},
+		{"cam || seeders >= 100", x265, true},
+		{"seeders > 100 AND NOT cam", cam, false},
+		{"quality >= 720p", cam, true},
+		{"quality > 720p", cam, false},
+		{"quality = hdtv", cam, false},
+		{"release >= web-dl", small, true},
+		{"release < web-dl", cam, true},
+		{"release:bluray", small, false},
+		{"age < 7d", x265, true},
+		{"age < 7d", small, false},
+		{"age < 7d", cam, false},
+		{"age > 1w", small, true},
+		{"trusted:false", small, true},
+		{"uploader != FOO", x265, false},
+		{"group:other", small, true},
+		{"codec:h264 and audio:eac3", small, true},
+		{"channels:5.1 and not hdr", x265, true},
+		{"title:/x26[45]-group$/", x265, true},
+		{"title != /camrip/", cam, false},
+		{"/web-?dl/", small, true},
+		{"size <= 900MB", cam, true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.expr, func(t *testing.T) {
+
+			expr, err := ParseFilterExpression(tt.expr)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok := expr.Matches(&tt.torrent); ok != tt.out {
+				t.Errorf("got %v, want %v for %v", ok, tt.out, tt.torrent.Title)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpressionErrors(t *testing.T) {
+
+	table := []string{
+		"",
+		"1080p and",
+		"(x265 or x264",
+		"x265)",
+		"size < big",
+		"seeders > many",
+		"quality >= 9000p",
+		"release > nope",
+		"age < 7 days",
+		"uploader > foo",
+		"colour:red",
+		"title:/(/",
+		`title:"unterminated`,
+		"trusted:maybe",
+		"seeders >",
+	}
+
+	for _, tt := range table {
+		t.Run(tt, func(t *testing.T) {
+
+			if _, err := ParseFilterExpression(tt); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestCombineFilterExpressions(t *testing.T) {
+
+	table := []struct {
+		in  []string
+		out string
+	}{
+		{nil, ""},
+		{[]string{"", " "}, ""},
+		{[]string{"x265", ""}, "x265"},
+		{[]string{"x265 or x264", "not cam"}, "(x265 or x264) and (not cam)"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.out, func(t *testing.T) {
+
+			if out := CombineFilterExpressions(tt.in...); out != tt.out {
+				t.Errorf("got %v, want %v", out, tt.out)
+			}
+		})
+	}
+}
+
+func TestSearchFiltersExpression(t *testing.T) {
+
+	filters := SearchFilters{Filter: "x265 or seeders > 100"}
+
+	torrents := []Torrent{
+		{Title: "Some.Movie.720p.x265", Seeders: 5},
+		{Title: "Some.Movie.720p.x264", Seeders: 5},
+		{Title: "Some.Movie.720p.x264", Seeders: 500},
+	}
+
+	if filtered := filters.FilterTorrents(torrents); len(filtered) != 2 {
+		t.Errorf("got %v torrents, want 2", len(filtered))
+	}
+
+	filters.Filter = "seeders >"
+
+	if filtered := filters.FilterTorrents(torrents); len(filtered) != 0 {
+		t.Errorf("invalid expressions should reject all torrents, got %v", len(filtered))
+	}
+
+	if err := filters.CompileFilter(); err == nil {
+		t.Errorf("expected an error for an invalid expression")
+	}
+
+	if _, err := PickVideoTorrent(torrents, filters); err == nil {
+		t.Errorf("expected an error when picking a torrent with an invalid expression")
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+
+	filters := SearchFilters{Filter: "x265"}
+
+	if err := filters.CompileFilter(); err != nil {
+		t.Fatal(err)
+	}
+
+	compiled, _ := filters.filterExpression()
+
+	if compiled != filters.expression || compiled == nil {
+		t.Errorf("the compiled expression was not reused")
+	}
+
+	// Changing the expression after compiling it is not ignored.
+	filters.Filter = "x264"
+
+	if !filters.IsOk(&Torrent{Title: "Some.Movie.720p.x264"}) {
+		t.Errorf("the filters used an outdated expression")
+	}
+}
@@ -0,0 +1,177 @@
+package torrents
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultTrackers holds the trackers that are added to the magnet links which are built from an info hash.
+// It can be replaced through the configuration.
+var DefaultTrackers = []string{
+	"udp://tracker.coppersurfer.tk:6969/announce",
+	"udp://9.rarbg.to:2920/announce",
+	"udp://tracker.opentrackr.org:1337",
+	"udp://tracker.internetwarriors.net:1337/announce",
+	"udp://tracker.leechers-paradise.org:6969/announce",
+	"udp://tracker.pirateparty.gr:6969/announce",
+	"udp://tracker.cyberia.is:6969/announce",
+}
+
+// Magnet holds the parameters of a magnet link.
+type Magnet struct {
+	// InfoHash is the BitTorrent v1 info hash of the torrent, in lowercase hex.
+	InfoHash string `json:"info_hash,omitempty"`
+
+	// MultiHash is the BitTorrent v2 info hash of the torrent, as a hex-encoded multihash.
+	MultiHash string `json:"multi_hash,omitempty"`
+
+	Name     string   `json:"name,omitempty"`
+	Length   int64    `json:"length,omitempty"`
+	Trackers []string `json:"trackers,omitempty"`
+	WebSeeds []string `json:"web_seeds,omitempty"`
+}
+
+// NewMagnet creates a magnet link for a torrent with the given info hash and name,
+// which also includes the DefaultTrackers.
+func NewMagnet(infoHash string, name string) Magnet {
+
+	if normalized, err := NormalizeInfoHash(infoHash); err == nil {
+		infoHash = normalized
+	}
+
+	magnet := Magnet{InfoHash: infoHash, Name: name}
+
+	return magnet.WithTrackers(DefaultTrackers...)
+}
+
+// ParseMagnet parses the parameters of the given magnet link.
+// The link needs to contain at least one btih or btmh info hash.
+func ParseMagnet(link string) (Magnet, error) {
+
+	var magnet Magnet
+
+	magnetURL, err := url.Parse(strings.TrimSpace(link))
+
+	if err != nil || magnetURL.Scheme != "magnet" {
+		return magnet, fmt.Errorf("invalid magnet link: %v", link)
+	}
+
+	params, err := url.ParseQuery(magnetURL.RawQuery)
+
+	if err != nil {
+		return magnet, fmt.Errorf("invalid magnet link: %v", link)
+	}
+
+	for _, xt := range params["xt"] {
+
+		if strings.HasPrefix(xt, "urn:btih:") {
+
+			magnet.InfoHash, err = NormalizeInfoHash(strings.TrimPrefix(xt, "urn:btih:"))
+
+		} else if strings.HasPrefix(xt, "urn:btmh:") {
+
+			magnet.MultiHash = strings.ToLower(strings.TrimPrefix(xt, "urn:btmh:"))
+
+			if _, hexErr := hex.DecodeString(magnet.MultiHash); hexErr != nil {
+				err = fmt.Errorf("invalid btmh info hash: %v", magnet.MultiHash)
+			}
+		}
+
+		if err != nil {
+			return magnet, err
+		}
+	}
+
+	if magnet.InfoHash == "" && magnet.MultiHash == "" {
+		return magnet, fmt.Errorf("magnet link without an info hash: %v", link)
+	}
+
+	magnet.Name = params.Get("dn")
+	magnet.Trackers = params["tr"]
+	magnet.WebSeeds = params["ws"]
+
+	if xl := params.Get("xl"); xl != "" {
+
+		if magnet.Length, err = strconv.ParseInt(xl, 10, 64); err != nil {
+			return magnet, fmt.Errorf("invalid exact length in magnet link: %v", xl)
+		}
+	}
+
+	return magnet, nil
+}
+
+// NormalizeInfoHash converts a v1 info hash, given either in hex or in base32, to lowercase hex.
+func NormalizeInfoHash(infoHash string) (string, error) {
+
+	infoHash = strings.TrimSpace(infoHash)
+
+	switch len(infoHash) {
+
+	case 40:
+		if _, err := hex.DecodeString(infoHash); err == nil {
+			return strings.ToLower(infoHash), nil
+		}
+
+	case 32:
+		if decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(infoHash)); err == nil {
+			return hex.EncodeToString(decoded), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid btih info hash: %v", infoHash)
+}
+
+// WithTrackers returns a copy of the magnet link with the given trackers appended to its own,
+// skipping any that it already contains.
+func (m Magnet) WithTrackers(trackers ...string) Magnet {
+
+	merged := append([]string{}, m.Trackers...)
+
+	for _, tracker := range trackers {
+
+		exists := false
+
+		for _, existing := range merged {
+			exists = exists || existing == tracker
+		}
+
+		if !exists && tracker != "" {
+			merged = append(merged, tracker)
+		}
+	}
+
+	m.Trackers = merged
+
+	return m
+}
+
+// String builds the magnet link.
+func (m Magnet) String() string {
+
+	var params []string
+
+	if m.InfoHash != "" {
+		params = append(params, "xt=urn:btih:"+m.InfoHash)
+	}
+	if m.MultiHash != "" {
+		params = append(params, "xt=urn:btmh:"+m.MultiHash)
+	}
+	if m.Name != "" {
+		params = append(params, "dn="+url.QueryEscape(m.Name))
+	}
+	if m.Length > 0 {
+		params = append(params, fmt.Sprintf("xl=%d", m.Length))
+	}
+	for _, tracker := range m.Trackers {
+		params = append(params, "tr="+url.QueryEscape(tracker))
+	}
+	for _, webSeed := range m.WebSeeds {
+		params = append(params, "ws="+url.QueryEscape(webSeed))
+	}
+
+	return "magnet:?" + strings.Join(params, "&")
+}
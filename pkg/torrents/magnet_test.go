@@ -0,0 +1,128 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeInfoHash(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out string
+		err bool
+	}{
+		{"BEE75372B98077BFD4DE8EF03EB33E9289BE5CD8", "bee75372b98077bfd4de8ef03eb33e9289be5cd8", false},
+		{"bee75372b98077bfd4de8ef03eb33e9289be5cd8", "bee75372b98077bfd4de8ef03eb33e9289be5cd8", false},
+		{"X3TVG4VZQB337VG6R3YD5MZ6SKE34XGY", "bee75372b98077bfd4de8ef03eb33e9289be5cd8", false},
+		{"x3tvg4vzqb337vg6r3yd5mz6ske34xgy", "bee75372b98077bfd4de8ef03eb33e9289be5cd8", false},
+		{"bee75372b98077bfd4de8ef03eb33e9289be5cz8", "", true},
+		{"bee75372", "", true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			s, err := NormalizeInfoHash(tt.in)
+
+			if s != tt.out || (err != nil) != tt.err {
+				t.Errorf("got %v, %v, want %v", s, err, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseMagnet(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out Magnet
+	}{
+		{
+			"magnet:?xt=urn:btih:BEE75372B98077BFD4DE8EF03EB33E9289BE5CD8&dn=Avengers+Infinity+War&tr=udp%3A%2F%2Ftracker.leechers-paradise.org%3A6969&tr=udp%3A%2F%2Fzer0day.ch%3A1337",
+			Magnet{
+				InfoHash: "bee75372b98077bfd4de8ef03eb33e9289be5cd8",
+				Name:     "Avengers Infinity War",
+				Trackers: []string{"udp://tracker.leechers-paradise.org:6969", "udp://zer0day.ch:1337"},
+			},
+		},
+		{
+			"magnet:?xt=urn:btih:X3TVG4VZQB337VG6R3YD5MZ6SKE34XGY&xl=1073741824&ws=https%3A%2F%2Fseed.example.org%2Ffile.mkv",
+			Magnet{
+				InfoHash: "bee75372b98077bfd4de8ef03eb33e9289be5cd8",
+				Length:   1073741824,
+				WebSeeds: []string{"https://seed.example.org/file.mkv"},
+			},
+		},
+		{
+			"magnet:?xt=urn:btmh:1220CAF1E1C30E81CB361B9EE167C4AA64228A7FA4FA9F6105232B28AD099F3A302E&dn=bittorrent-v2-test",
+			Magnet{
+				MultiHash: "1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e",
+				Name:      "bittorrent-v2-test",
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.out.Name, func(t *testing.T) {
+
+			m, err := ParseMagnet(tt.in)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(m, tt.out) {
+				t.Errorf("got %+v, want %+v", m, tt.out)
+			}
+
+			reparsed, err := ParseMagnet(m.String())
+
+			if err != nil || !reflect.DeepEqual(reparsed, m) {
+				t.Errorf("got %+v, %v after rebuilding %v", reparsed, err, m.String())
+			}
+		})
+	}
+}
+
+func TestParseMagnetErrors(t *testing.T) {
+
+	table := []string{
+		"https://example.org/?xt=urn:btih:bee75372b98077bfd4de8ef03eb33e9289be5cd8",
+		"magnet:?dn=No+Hash",
+		"magnet:?xt=urn:btih:nothex",
+		"magnet:?xt=urn:btmh:zz",
+		"magnet:?xt=urn:btih:bee75372b98077bfd4de8ef03eb33e9289be5cd8&xl=big",
+	}
+
+	for _, in := range table {
+		t.Run(in, func(t *testing.T) {
+
+			if m, err := ParseMagnet(in); err == nil {
+				t.Errorf("expected error, got %+v", m)
+			}
+		})
+	}
+}
+
+func TestNewMagnet(t *testing.T) {
+
+	defaultTrackers := DefaultTrackers
+	defer func() { DefaultTrackers = defaultTrackers }()
+
+	DefaultTrackers = []string{"udp://tracker.opentrackr.org:1337/announce"}
+
+	m := NewMagnet("BEE75372B98077BFD4DE8EF03EB33E9289BE5CD8", "Avengers Infinity War")
+
+	want := "magnet:?xt=urn:btih:bee75372b98077bfd4de8ef03eb33e9289be5cd8&dn=Avengers+Infinity+War&tr=udp%3A%2F%2Ftracker.opentrackr.org%3A1337%2Fannounce"
+
+	if m.String() != want {
+		t.Errorf("got %v, want %v", m.String(), want)
+	}
+
+	m = m.WithTrackers("udp://tracker.opentrackr.org:1337/announce", "udp://tracker.cyberia.is:6969")
+
+	if !reflect.DeepEqual(m.Trackers, []string{"udp://tracker.opentrackr.org:1337/announce", "udp://tracker.cyberia.is:6969"}) {
+		t.Errorf("got %v", m.Trackers)
+	}
+}
@@ -0,0 +1,90 @@
+package torrents
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+// DefaultProxyListTTL is the time for which a fetched list of Pirate Bay mirrors is used, when the filters do not specify one.
+const DefaultProxyListTTL = 6 * time.Hour
+
+// proxyListCache holds a list of Pirate Bay mirrors as it was fetched from a proxy list.
+type proxyListCache struct {
+	Source  string    `json:"source"`
+	Fetched time.Time `json:"fetched"`
+	Mirrors []Mirror  `json:"mirrors"`
+}
+
+func loadProxyListCache(path string, source string) (*proxyListCache, error) {
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cache proxyListCache
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+
+	if cache.Source != source {
+		return nil, os.ErrNotExist
+	}
+
+	return &cache, nil
+}
+
+func (c *proxyListCache) save(path string) error {
+
+	data, err := json.MarshalIndent(c, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func (c *proxyListCache) fresh(ttl time.Duration) bool {
+
+	return time.Since(c.Fetched) < ttl
+}
+
+// GetCacheTTL returns the time for which a fetched list of mirrors is used before it is fetched again.
+func (m MirrorFilters) GetCacheTTL() time.Duration {
+
+	if m.CacheTTL == "" {
+		return DefaultProxyListTTL
+	}
+
+	ttl, err := utils.ParseDuration(m.CacheTTL)
+
+	if err != nil {
+		return DefaultProxyListTTL
+	}
+
+	return ttl
+}
+
+// staticMirrors returns the mirrors that are defined in the filters, which are always considered to be up.
+func (m MirrorFilters) staticMirrors() []Mirror {
+
+	var mirrors []Mirror
+
+	for _, url := range m.Static {
+		mirrors = append(mirrors, Mirror{URL: url, Status: true})
+	}
+
+	return mirrors
+}
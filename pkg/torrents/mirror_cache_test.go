@@ -0,0 +1,93 @@
+package torrents
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetMirrorsCache(t *testing.T) {
+
+	page, err := ioutil.ReadFile("../../test_samples/proxybay.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(page)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "goirate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scraper := NewMirrorScraper(server.URL, MirrorFilters{Static: []string{"https://my.mirror", "https://pirateproxy.cloud"}})
+	scraper.SetCachePath(filepath.Join(dir, "proxies.json"))
+
+	mirrors, err := scraper.GetMirrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mirrors) != 17 || mirrors[0].URL != "https://my.mirror" || mirrors[1].URL != "https://pirateproxy.cloud" || !mirrors[0].Status {
+		t.Errorf("got %v mirrors starting with %v, want 17 starting with the static ones", len(mirrors), mirrors[:2])
+	}
+
+	// The cached list is used while it is fresh.
+	if _, err := scraper.GetMirrors(); err != nil || requests != 1 {
+		t.Errorf("got %v requests and %v, want 1 request", requests, err)
+	}
+
+	// An expired list is fetched again.
+	scraper.mirrorFilters.CacheTTL = "0s"
+	if _, err := scraper.GetMirrors(); err != nil || requests != 2 {
+		t.Errorf("got %v requests and %v, want 2 requests", requests, err)
+	}
+
+	// An expired list is still used while the proxy list is unreachable.
+	server.Close()
+	mirrors, err = scraper.GetMirrors()
+	if err != nil || len(mirrors) != 17 {
+		t.Errorf("got %v mirrors and %v, want the 17 cached ones", len(mirrors), err)
+	}
+
+	// Without a cache, only the static mirrors are left.
+	scraper.SetCachePath(filepath.Join(dir, "missing.json"))
+	mirrors, err = scraper.GetMirrors()
+	if err != nil || len(mirrors) != 2 {
+		t.Errorf("got %v mirrors and %v, want the 2 static ones", len(mirrors), err)
+	}
+
+	scraper.mirrorFilters.Static = nil
+	if _, err := scraper.GetMirrors(); err == nil {
+		t.Errorf("expected an error without any mirrors")
+	}
+}
+
+func TestGetCacheTTL(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out time.Duration
+	}{
+		{"", DefaultProxyListTTL},
+		{"30m", 30 * time.Minute},
+		{"1d", 24 * time.Hour},
+		{"invalid", DefaultProxyListTTL},
+	}
+
+	for _, tt := range table {
+		if out := (MirrorFilters{CacheTTL: tt.in}).GetCacheTTL(); out != tt.out {
+			t.Errorf("got %v, want %v for %v", out, tt.out, tt.in)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package torrents
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMirrorMaxFailures is the number of consecutive failed searches after which a mirror is blacklisted,
+// when the filters do not specify one.
+const DefaultMirrorMaxFailures = 5
+
+// MirrorBlacklistDuration is the time for which a mirror that keeps failing is skipped, before it is tried again.
+const MirrorBlacklistDuration = 24 * time.Hour
+
+// MirrorStats holds the statistics of the searches made on a Pirate Bay mirror.
+type MirrorStats struct {
+	URL                 string    `json:"url"`
+	Successes           int       `json:"successes"`
+	Failures            int       `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	TotalLatencyMS      int64     `json:"total_latency_ms"`
+	TotalResults        int       `json:"total_results"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastFailure         time.Time `json:"last_failure"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// SuccessRate returns the fraction of the searches on the mirror that succeeded.
+func (s MirrorStats) SuccessRate() float64 {
+
+	if s.Successes+s.Failures == 0 {
+		return 0
+	}
+
+	return float64(s.Successes) / float64(s.Successes+s.Failures)
+}
+
+// AverageLatency returns the average time it took for the successful searches on the mirror to complete.
+func (s MirrorStats) AverageLatency() time.Duration {
+
+	if s.Successes == 0 {
+		return 0
+	}
+
+	return time.Duration(s.TotalLatencyMS/int64(s.Successes)) * time.Millisecond
+}
+
+// AverageResults returns the average number of torrents returned by the successful searches on the mirror.
+func (s MirrorStats) AverageResults() float64 {
+
+	if s.Successes == 0 {
+		return 0
+	}
+
+	return float64(s.TotalResults) / float64(s.Successes)
+}
+
+// Blacklisted returns true if the mirror has failed the given number of consecutive times,
+// with the last failure being recent enough for it to be skipped. A non-positive number never blacklists a mirror.
+func (s MirrorStats) Blacklisted(maxFailures int) bool {
+
+	return maxFailures > 0 && s.ConsecutiveFailures >= maxFailures && time.Since(s.LastFailure) < MirrorBlacklistDuration
+}
+
+// score ranks mirrors by their success rate, and then by their latency.
+// Mirrors without any history are placed after the reliable ones, but before the ones that usually fail.
+func (s MirrorStats) score() float64 {
+
+	if s.Successes+s.Failures == 0 {
+		return 0.5
+	}
+
+	latency := s.AverageLatency().Seconds()
+
+	return s.SuccessRate() / (1 + math.Max(0, latency)/10) * math.Pow(0.5, float64(s.ConsecutiveFailures))
+}
+
+// MirrorStatsDB holds the statistics of all Pirate Bay mirrors that have been searched, persisted in a file.
+type MirrorStatsDB struct {
+	path    string
+	mutex   sync.Mutex
+	mirrors map[string]*MirrorStats
+}
+
+// LoadMirrorStats loads the statistics of the mirrors from the file at the given path.
+// If the file does not exist, the database starts out empty.
+func LoadMirrorStats(path string) (*MirrorStatsDB, error) {
+
+	db := NewMirrorStats()
+	db.path = path
+
+	data, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return db, nil
+	} else if err != nil {
+		return db, err
+	}
+
+	var stats []MirrorStats
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return db, err
+	}
+
+	for i := range stats {
+		db.mirrors[stats[i].URL] = &stats[i]
+	}
+
+	return db, nil
+}
+
+// NewMirrorStats creates an empty database of mirror statistics, which is not persisted.
+func NewMirrorStats() *MirrorStatsDB {
+
+	return &MirrorStatsDB{mirrors: make(map[string]*MirrorStats)}
+}
+
+// Save writes the statistics of the mirrors to the file the database was loaded from.
+// Databases that were not loaded from a file are not saved.
+func (db *MirrorStatsDB) Save() error {
+
+	if db.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(db.List(), "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(db.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(db.path, data, 0644)
+}
+
+// Record adds the outcome of a search on a mirror to its statistics.
+func (db *MirrorStatsDB) Record(url string, latency time.Duration, results int, err error) {
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	stats, exists := db.mirrors[url]
+
+	if !exists {
+		stats = &MirrorStats{URL: url}
+		db.mirrors[url] = stats
+	}
+
+	if err != nil {
+
+		stats.Failures++
+		stats.ConsecutiveFailures++
+		stats.LastFailure = time.Now()
+		stats.LastError = err.Error()
+
+	} else {
+
+		stats.Successes++
+		stats.ConsecutiveFailures = 0
+		stats.TotalLatencyMS += int64(latency / time.Millisecond)
+		stats.TotalResults += results
+		stats.LastSuccess = time.Now()
+	}
+}
+
+// Get returns the statistics of the mirror with the given URL.
+func (db *MirrorStatsDB) Get(url string) MirrorStats {
+
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	if stats, exists := db.mirrors[url]; exists {
+		return *stats
+	}
+
+	return MirrorStats{URL: url}
+}
+
+// List returns the statistics of all mirrors, from the most to the least reliable.
+func (db *MirrorStatsDB) List() []MirrorStats {
+
+	db.mutex.Lock()
+
+	var stats []MirrorStats
+	for _, s := range db.mirrors {
+		stats = append(stats, *s)
+	}
+
+	db.mutex.Unlock()
+
+	sort.SliceStable(stats, func(i, j int) bool {
+
+		if stats[i].score() != stats[j].score() {
+			return stats[i].score() > stats[j].score()
+		}
+
+		return stats[i].URL < stats[j].URL
+	})
+
+	return stats
+}
+
+// Rank sorts the given mirrors from the most to the least reliable, skipping the ones that are blacklisted.
+// Mirrors with equal statistics keep their order.
+func (db *MirrorStatsDB) Rank(mirrors []Mirror, maxFailures int) []Mirror {
+
+	var ranked []Mirror
+
+	for _, mirror := range mirrors {
+		if !db.Get(mirror.URL).Blacklisted(maxFailures) {
+			ranked = append(ranked, mirror)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return db.Get(ranked[i].URL).score() > db.Get(ranked[j].URL).score()
+	})
+
+	return ranked
+}
+
+// Timeout returns how long to wait for a search on the mirror, based on its average latency.
+func (db *MirrorStatsDB) Timeout(url string, defaultTimeout time.Duration) time.Duration {
+
+	latency := db.Get(url).AverageLatency()
+
+	if latency == 0 {
+		return defaultTimeout
+	}
+
+	timeout := 3 * latency
+
+	if timeout < defaultTimeout/2 {
+		timeout = defaultTimeout / 2
+	}
+	if timeout > 2*defaultTimeout {
+		timeout = 2 * defaultTimeout
+	}
+
+	return timeout
+}
@@ -0,0 +1,160 @@
+package torrents
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMirrorStatsRecord(t *testing.T) {
+
+	db := NewMirrorStats()
+
+	db.Record("https://a.org", 200*time.Millisecond, 30, nil)
+	db.Record("https://a.org", 400*time.Millisecond, 10, nil)
+	db.Record("https://a.org", time.Second, 0, errors.New("timeout"))
+
+	stats := db.Get("https://a.org")
+
+	if rate := stats.SuccessRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("got success rate %v, want 0.67", rate)
+	}
+	if latency := stats.AverageLatency(); latency != 300*time.Millisecond {
+		t.Errorf("got latency %v, want 300ms", latency)
+	}
+	if results := stats.AverageResults(); results != 20 {
+		t.Errorf("got %v results, want 20", results)
+	}
+	if stats.ConsecutiveFailures != 1 || stats.LastError != "timeout" || stats.LastFailure.IsZero() {
+		t.Errorf("failure not recorded: %+v", stats)
+	}
+
+	db.Record("https://a.org", 300*time.Millisecond, 30, nil)
+
+	if stats := db.Get("https://a.org"); stats.ConsecutiveFailures != 0 {
+		t.Errorf("got %v consecutive failures, want 0", stats.ConsecutiveFailures)
+	}
+
+	if stats := db.Get("https://unknown.org"); stats.URL != "https://unknown.org" || stats.SuccessRate() != 0 {
+		t.Errorf("got %+v for an unknown mirror", stats)
+	}
+}
+
+func TestMirrorStatsBlacklisted(t *testing.T) {
+
+	table := []struct {
+		stats       MirrorStats
+		maxFailures int
+		out         bool
+	}{
+		{MirrorStats{}, 5, false},
+		{MirrorStats{ConsecutiveFailures: 4, LastFailure: time.Now()}, 5, false},
+		{MirrorStats{ConsecutiveFailures: 5, LastFailure: time.Now()}, 5, true},
+		{MirrorStats{ConsecutiveFailures: 5, LastFailure: time.Now().Add(-2 * MirrorBlacklistDuration)}, 5, false},
+		{MirrorStats{ConsecutiveFailures: 50, LastFailure: time.Now()}, -1, false},
+	}
+
+	for _, tt := range table {
+
+		if out := tt.stats.Blacklisted(tt.maxFailures); out != tt.out {
+			t.Errorf("got %v, want %v for %+v", out, tt.out, tt.stats)
+		}
+	}
+}
+
+func TestMirrorStatsRank(t *testing.T) {
+
+	db := NewMirrorStats()
+
+	for i := 0; i < 5; i++ {
+		db.Record("https://failing.org", time.Second, 0, errors.New("timeout"))
+	}
+	db.Record("https://slow.org", 3*time.Second, 30, nil)
+	db.Record("https://fast.org", 100*time.Millisecond, 30, nil)
+	db.Record("https://flaky.org", 100*time.Millisecond, 30, nil)
+	db.Record("https://flaky.org", time.Second, 0, errors.New("timeout"))
+
+	mirrors := []Mirror{
+		{URL: "https://failing.org"},
+		{URL: "https://flaky.org"},
+		{URL: "https://new.org"},
+		{URL: "https://slow.org"},
+		{URL: "https://fast.org"},
+	}
+
+	var urls []string
+	for _, mirror := range db.Rank(mirrors, 5) {
+		urls = append(urls, mirror.URL)
+	}
+
+	expected := []string{"https://fast.org", "https://slow.org", "https://new.org", "https://flaky.org"}
+
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("got %v, want %v", urls, expected)
+	}
+
+	if len(db.Rank(mirrors, -1)) != len(mirrors) {
+		t.Errorf("mirrors should not be skipped when blacklisting is disabled")
+	}
+}
+
+func TestMirrorStatsTimeout(t *testing.T) {
+
+	db := NewMirrorStats()
+
+	db.Record("https://fast.org", 100*time.Millisecond, 30, nil)
+	db.Record("https://normal.org", time.Second, 30, nil)
+	db.Record("https://slow.org", 5*time.Second, 30, nil)
+
+	table := []struct {
+		url string
+		out time.Duration
+	}{
+		{"https://new.org", 4 * time.Second},
+		{"https://fast.org", 2 * time.Second},
+		{"https://normal.org", 3 * time.Second},
+		{"https://slow.org", 8 * time.Second},
+	}
+
+	for _, tt := range table {
+		if out := db.Timeout(tt.url, 4*time.Second); out != tt.out {
+			t.Errorf("got %v, want %v for %v", out, tt.out, tt.url)
+		}
+	}
+}
+
+func TestMirrorStatsSaveLoad(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "goirate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "stats", "mirrors.json")
+
+	db, err := LoadMirrorStats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Record("https://a.org", 200*time.Millisecond, 30, nil)
+	db.Record("https://b.org", time.Second, 0, errors.New("timeout"))
+
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadMirrorStats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a, b := loaded.List(), db.List(); len(a) != 2 || a[0].URL != b[0].URL || a[1].Failures != 1 || a[0].TotalLatencyMS != 200 {
+		t.Errorf("got %+v, want %+v", a, b)
+	}
+}
@@ -1,11 +1,14 @@
 package torrents
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,8 +40,11 @@ func FallbackMirror() Mirror {
 // MirrorScraper holds the url to a torrents proxy list.
 // By default the scraper will use proxybay.github.io.
 type MirrorScraper struct {
+	ctx            context.Context
 	proxySourceURL string
 	mirrorFilters  MirrorFilters
+	statsPath      string
+	cachePath      string
 }
 
 // MirrorFilters define filters for picking a Pirate Bay mirror.
@@ -46,6 +52,32 @@ type MirrorFilters struct {
 	Preferred string   `toml:"preferred"`
 	Whitelist []string `toml:"whitelist"`
 	Blacklist []string `toml:"blacklist"`
+
+	// Timezone is the IANA name of the timezone in which mirrors display upload times, such as Europe/Stockholm.
+	Timezone string `toml:"timezone"`
+
+	// MaxFailures is the number of consecutive failed searches after which a mirror is skipped for a while.
+	// Defaults to DefaultMirrorMaxFailures, while a negative number never skips mirrors.
+	MaxFailures int `toml:"max_failures"`
+
+	// Static holds the URLs of mirrors that are always searched, in addition to the ones on the proxy list.
+	Static []string `toml:"static"`
+
+	// CacheTTL is the time for which a fetched proxy list is used, such as 6h or 1d, before it is fetched again.
+	CacheTTL string `toml:"cache_ttl"`
+
+	// Pages is the number of pages of search results that are read on each mirror, defaulting to one.
+	Pages int `toml:"pages"`
+
+	// MaxResults stops reading further pages once this many torrents have been found, with zero reading all of them.
+	MaxResults int `toml:"max_results"`
+
+	// Category limits the searches to a Pirate Bay category, such as 200 for video, with zero searching all of them.
+	Category int `toml:"category"`
+
+	// OrderBy is the Pirate Bay ordering of the search results, such as 7 for the most seeders first.
+	// Defaults to DefaultSearchOrder.
+	OrderBy int `toml:"order_by"`
 }
 
 // NewMirrorScraper initializes a new scraper for a list of piratebay mirrors.
@@ -63,6 +95,42 @@ func (m *MirrorScraper) SetProxySourceURL(url string) {
 	m.proxySourceURL = url
 }
 
+// SetStatsPath sets the file in which the statistics of the searches on each mirror are kept,
+// which are used to rank the mirrors and skip the ones that keep failing.
+func (m *MirrorScraper) SetStatsPath(path string) {
+	m.statsPath = path
+}
+
+// SetCachePath sets the file in which the fetched proxy list is cached.
+func (m *MirrorScraper) SetCachePath(path string) {
+	m.cachePath = path
+}
+
+// SetContext sets the context which cancels the scraper's requests, when it is cancelled or expires.
+func (m *MirrorScraper) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// context returns the context of the scraper's requests.
+func (m *MirrorScraper) context() context.Context {
+
+	if m.ctx == nil {
+		return context.Background()
+	}
+
+	return m.ctx
+}
+
+// GetStats loads the statistics of the searches on each mirror.
+func (m *MirrorScraper) GetStats() (*MirrorStatsDB, error) {
+
+	if m.statsPath == "" {
+		return NewMirrorStats(), nil
+	}
+
+	return LoadMirrorStats(m.statsPath)
+}
+
 // GetProxySourceURL retrieves the current URL at which the scraper will attempt to fetch a list
 // of Pirate Bay proxies from.
 func (m *MirrorScraper) GetProxySourceURL() string {
@@ -72,16 +140,86 @@ func (m *MirrorScraper) GetProxySourceURL() string {
 	return m.proxySourceURL
 }
 
-// GetMirrors retrieves a list of PirateBay mirrors.
+// GetMirrors retrieves a list of PirateBay mirrors, starting with the static mirrors of the filters.
+// When a cache path is set, the proxy list is only fetched again once the cached one expires,
+// and the expired one is used while the proxy list is unreachable.
 func (m *MirrorScraper) GetMirrors() ([]Mirror, error) {
 
-	doc, err := utils.HTTPGet(m.GetProxySourceURL())
+	fetched, err := m.getProxyList()
 
-	if err != nil {
+	mirrors := m.mirrorFilters.staticMirrors()
+
+	for _, mirror := range fetched {
+
+		duplicate := false
+		for _, existing := range mirrors {
+			duplicate = duplicate || existing.URL == mirror.URL
+		}
+
+		if !duplicate && m.mirrorFilters.IsOk(mirror) {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+
+	if err != nil && len(mirrors) == 0 {
 		return nil, err
 	}
 
-	return m.parseMirrors(doc), nil
+	if mirrors == nil {
+		mirrors = make([]Mirror, 0)
+	}
+
+	return mirrors, nil
+}
+
+// getProxyList returns the unfiltered list of mirrors, either from the cache or by fetching the proxy list.
+func (m *MirrorScraper) getProxyList() ([]Mirror, error) {
+
+	source := m.GetProxySourceURL()
+
+	var cache *proxyListCache
+
+	if m.cachePath != "" {
+
+		cache, _ = loadProxyListCache(m.cachePath, source)
+
+		if cache != nil && cache.fresh(m.mirrorFilters.GetCacheTTL()) {
+			return cache.Mirrors, nil
+		}
+	}
+
+	client := utils.HTTPClient{Category: utils.TorrentRequests, Context: m.context()}
+	doc, err := client.Get(source)
+
+	var mirrors []Mirror
+	if err == nil {
+		mirrors = parseAllMirrors(doc)
+	}
+
+	if len(mirrors) == 0 {
+
+		if cache != nil {
+
+			if os.Getenv("GOIRATE_DEBUG") == "true" {
+				log.Printf("using the proxy list from %v, since fetching it failed: %v\n", cache.Fetched, err)
+			}
+
+			return cache.Mirrors, nil
+		}
+
+		return nil, err
+	}
+
+	if m.cachePath != "" {
+
+		cache = &proxyListCache{Source: source, Fetched: time.Now(), Mirrors: mirrors}
+
+		if err := cache.save(m.cachePath); err != nil && os.Getenv("GOIRATE_DEBUG") == "true" {
+			log.Printf("failed to cache the proxy list: %v\n", err)
+		}
+	}
+
+	return mirrors, nil
 }
 
 // GetTorrents fetches all available Pirate Bay mirrors and returns the first Pirate Bay page that it finds.
@@ -93,7 +231,7 @@ func (m *MirrorScraper) GetTorrents(query string) ([]Torrent, error) {
 		return nil, err
 	}
 
-	_, torrents, err := m.getTorrents(mirrors, query, true)
+	_, torrents, err := m.searchMirrors(mirrors, query)
 
 	return torrents, err
 }
@@ -107,11 +245,79 @@ func (m *MirrorScraper) PickMirror(query string) (*Mirror, error) {
 		return nil, err
 	}
 
-	mirror, _, err := m.getTorrents(mirrors, query, true)
+	mirror, _, err := m.searchMirrors(mirrors, query)
 
 	return mirror, err
 }
 
+// Validate returns an error if the timezone of the mirrors is invalid, since upload times would otherwise be off by hours.
+func (m MirrorFilters) Validate() error {
+
+	if m.Timezone == "" {
+		return nil
+	}
+
+	if _, err := time.LoadLocation(m.Timezone); err != nil {
+		return fmt.Errorf("tpb_mirrors: invalid timezone %v: %v", m.Timezone, err)
+	}
+
+	return nil
+}
+
+// Location returns the timezone in which mirrors display upload times, defaulting to UTC if none or an invalid one is set.
+func (m MirrorFilters) Location() *time.Location {
+
+	if m.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(m.Timezone)
+
+	if err != nil {
+
+		if os.Getenv("GOIRATE_DEBUG") == "true" {
+			log.Printf("invalid mirror timezone %v: %v\n", m.Timezone, err)
+		}
+
+		return time.UTC
+	}
+
+	return loc
+}
+
+// GetMaxFailures returns the number of consecutive failed searches after which a mirror is skipped.
+func (m MirrorFilters) GetMaxFailures() int {
+
+	if m.MaxFailures == 0 {
+		return DefaultMirrorMaxFailures
+	}
+
+	return m.MaxFailures
+}
+
+// GetPages returns the number of pages of search results that are read on each mirror.
+func (m MirrorFilters) GetPages() int {
+
+	if m.Pages < 1 {
+		return 1
+	}
+
+	return m.Pages
+}
+
+// Scraper returns a scraper for the given mirror, which searches it according to the filters.
+func (m MirrorFilters) Scraper(mirrorURL string) PirateBayScaper {
+
+	scraper := NewScraper(mirrorURL).(*pirateBayScaper)
+	scraper.SetTimezone(m.Location())
+	scraper.pages = m.GetPages()
+	scraper.maxResults = m.MaxResults
+	scraper.category = m.Category
+	scraper.orderBy = m.OrderBy
+
+	return scraper
+}
+
 // IsOk returns true if the given mirror complies with the filters.
 func (m *MirrorFilters) IsOk(mirror Mirror) bool {
 
@@ -145,6 +351,21 @@ func (m *MirrorScraper) parseMirrors(doc *goquery.Document) []Mirror {
 
 	mirrors := make([]Mirror, 0)
 
+	for _, mirror := range parseAllMirrors(doc) {
+
+		if m.mirrorFilters.IsOk(mirror) {
+
+			mirrors = append(mirrors, mirror)
+		}
+	}
+
+	return mirrors
+}
+
+func parseAllMirrors(doc *goquery.Document) []Mirror {
+
+	mirrors := make([]Mirror, 0)
+
 	doc.Find("#proxyList > tbody > tr").Each(func(i int, s *goquery.Selection) {
 
 		site, _ := s.Find(".site a").Attr("href")
@@ -153,97 +374,181 @@ func (m *MirrorScraper) parseMirrors(doc *goquery.Document) []Mirror {
 
 		country = strings.ToUpper(country)
 
-		mirror := Mirror{site, country, status == "up"}
-
-		if m.mirrorFilters.IsOk(mirror) {
-
-			mirrors = append(mirrors, mirror)
-		}
+		mirrors = append(mirrors, Mirror{site, country, status == "up"})
 	})
 
 	return mirrors
 }
 
-func (m *MirrorScraper) getTorrents(mirrors []Mirror, query string, trustSource bool) (*Mirror, []Torrent, error) {
+// searchMirrors searches the given mirrors using their statistics, which are then updated with the outcome of the searches.
+func (m *MirrorScraper) searchMirrors(mirrors []Mirror, query string) (*Mirror, []Torrent, error) {
+
+	stats, err := m.GetStats()
+
+	if err != nil && os.Getenv("GOIRATE_DEBUG") == "true" {
+		log.Printf("failed to load the mirror statistics: %v\n", err)
+	}
+
+	mirror, torrents, err := m.getTorrents(mirrors, query, true, stats, nil)
+
+	if saveErr := stats.Save(); saveErr != nil && os.Getenv("GOIRATE_DEBUG") == "true" {
+		log.Printf("failed to save the mirror statistics: %v\n", saveErr)
+	}
+
+	return mirror, torrents, err
+}
+
+// getTorrents searches the mirrors and records the outcome of each search in the statistics. When trustSource is set,
+// only the mirrors that are up according to the proxy list are searched at first, and the rest only if none of them returned
+// any results. The mirrors in searched are skipped, so that each mirror is searched and recorded at most once.
+func (m *MirrorScraper) getTorrents(mirrors []Mirror, query string, trustSource bool, stats *MirrorStatsDB, searched map[string]bool) (*Mirror, []Torrent, error) {
 
 	type torrentResponse struct {
-		mirror   *Mirror
+		mirror   Mirror
 		torrents []Torrent
+		latency  time.Duration
+		err      error
 	}
 
-	var workingMirror *Mirror
+	// Mirrors that keep failing are skipped, and the rest are ranked from the most reliable to the least.
+	candidates := append(append([]Mirror{}, mirrors...), FallbackMirror())
+	ranked := stats.Rank(candidates, m.mirrorFilters.GetMaxFailures())
 
 	if m.mirrorFilters.Preferred != "" {
 
-		mirrors = append([]Mirror{{URL: m.mirrorFilters.Preferred}}, mirrors...)
+		// The preferred mirror is never skipped.
+		ranked = append([]Mirror{{URL: m.mirrorFilters.Preferred}}, ranked...)
 	}
 
-	mirrors = append(mirrors, FallbackMirror())
+	defaultTimeout := 4 * time.Second
 
-	timeout := 4 * time.Second
+	// Outstanding searches are aborted once a response has been put together.
+	ctx, cancel := context.WithCancel(m.context())
+	defer cancel()
 
-	channel := make(chan torrentResponse)
+	// The channel is buffered, so that the searches can finish even if the responses are no longer collected.
+	channel := make(chan torrentResponse, len(ranked))
 
 	searchMirror := func(mirror Mirror) {
 
-		scraper := NewScraper(mirror.URL)
+		scraper := m.mirrorFilters.Scraper(mirror.URL).(*pirateBayScaper)
 
-		torrents, err := scraper.SearchTimeout(query, timeout)
+		// The timeout applies to each request, while retries and waiting on the rate limit are capped altogether.
+		timeout := stats.Timeout(mirror.URL, defaultTimeout)
 
-		if len(torrents) > 0 {
-			workingMirror = &mirror
-		}
+		searchCtx, cancelSearch := context.WithTimeout(ctx, time.Duration(3*scraper.pages)*timeout)
+		defer cancelSearch()
+
+		start := time.Now()
+
+		torrents, err := scraper.search(searchCtx, query, timeout)
 
 		if os.Getenv("GOIRATE_DEBUG") == "true" {
 			log.Printf("%v -> %v, %v\n", mirror.URL, len(torrents), err)
 		}
 
-		if len(torrents) > 0 {
-
-			channel <- torrentResponse{&mirror, torrents}
+		channel <- torrentResponse{mirror, torrents, time.Since(start), err}
+	}
 
-		} else {
+	up := make(map[string]bool)
+	for _, mirror := range ranked {
+		up[mirror.URL] = up[mirror.URL] || mirror.Status
+	}
 
-			channel <- torrentResponse{nil, nil}
-		}
+	if searched == nil {
+		searched = make(map[string]bool)
 	}
 
-	// Return the first mirror that responds to HTTP GET
 	requestsSent := 0
-	for _, mirror := range mirrors {
+	for _, mirror := range ranked {
 
-		if !mirror.Status && trustSource {
+		if searched[mirror.URL] || (trustSource && !up[mirror.URL]) {
 			continue
 		}
 
+		searched[mirror.URL] = true
+
 		go searchMirror(mirror)
 
 		requestsSent++
 	}
 
-	var allTorrents []Torrent
+	responses := make(map[string]torrentResponse)
 
+collect:
 	for i := 0; i < requestsSent; i++ {
 
-		resp := <-channel
+		select {
+
+		case resp := <-channel:
+			responses[resp.mirror.URL] = resp
+
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	var workingMirror *Mirror
+	var allTorrents []Torrent
+
+	for _, mirror := range ranked {
+
+		resp, searched := responses[mirror.URL]
+
+		if !searched {
+			continue
+		}
+
+		mirror := mirror
+
+		if len(resp.torrents) > 0 {
 
-		if resp.torrents != nil {
 			allTorrents = append(allTorrents, resp.torrents...)
+
+			// The working mirror is the most reliable one that returned results.
+			if workingMirror == nil {
+				workingMirror = &mirror
+			}
 		}
 	}
 
-	if len(allTorrents) > 0 {
-		return workingMirror, allTorrents, nil
+	for _, resp := range responses {
+
+		err := resp.err
+
+		if len(resp.torrents) > 0 {
+			// Mirrors usually serve only one of the search URL formats, so the errors of the others are expected.
+			err = nil
+		} else if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			// The search was interrupted, which says nothing about the mirror.
+			continue
+		}
+
+		if err == nil && len(resp.torrents) == 0 && len(allTorrents) > 0 {
+			// Other mirrors found results, so this one most likely did not serve a proper search page.
+			err = errors.New("no results")
+		}
+
+		stats.Record(resp.mirror.URL, resp.latency, len(resp.torrents), err)
 	}
 
-	if trustSource {
+	if ctx.Err() != nil {
+
+		return nil, nil, ctx.Err()
+	}
 
-		return m.getTorrents(mirrors, query, false)
+	if len(allTorrents) > 0 {
+
+		// Mirrors of the same site will return mostly the same torrents.
+		allTorrents = DeduplicateTorrents(allTorrents)
+		sort.Stable(sortBySeeds(allTorrents))
+
+		return workingMirror, allTorrents, nil
 	}
 
-	if workingMirror != nil {
+	if trustSource {
 
-		return workingMirror, []Torrent{}, nil
+		return m.getTorrents(mirrors, query, false, stats, searched)
 	}
 
 	return nil, nil, errors.New("all Pirate Bay proxies seem to be unreachable")
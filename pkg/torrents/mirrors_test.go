@@ -1,8 +1,14 @@
 package torrents
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/PuerkitoBio/goquery"
@@ -25,6 +31,28 @@ func TestMirrorScraper(t *testing.T) {
 	}
 }
 
+func TestMirrorFiltersValidate(t *testing.T) {
+
+	table := []struct {
+		timezone string
+		err      bool
+	}{
+		{"", false},
+		{"Europe/Stockholm", false},
+		{"UTC", false},
+		{"Europe/Nowhere", true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.timezone, func(t *testing.T) {
+
+			if err := (MirrorFilters{Timezone: tt.timezone}).Validate(); (err != nil) != tt.err {
+				t.Errorf("got error %v, want error %v", err, tt.err)
+			}
+		})
+	}
+}
+
 func TestIsOk(t *testing.T) {
 	var mf MirrorFilters
 
@@ -132,7 +160,7 @@ func TestPickMirror(t *testing.T) {
 
 	mirrors := mirrorScraper.parseMirrors(doc)
 
-	_, torrents, err := mirrorScraper.getTorrents(mirrors, "ubuntu", true)
+	_, torrents, err := mirrorScraper.getTorrents(mirrors, "ubuntu", true, NewMirrorStats(), nil)
 
 	if err != nil {
 		t.Error(err)
@@ -143,6 +171,31 @@ func TestPickMirror(t *testing.T) {
 	}
 }
 
+func TestGetTorrentsCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mirrorScraper := NewMirrorScraper("", MirrorFilters{})
+	mirrorScraper.SetContext(ctx)
+
+	stats := NewMirrorStats()
+
+	_, torrents, err := mirrorScraper.getTorrents([]Mirror{{URL: "https://localhost:1", Status: true}}, "ubuntu", true, stats, nil)
+
+	if err != context.Canceled {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+
+	if len(torrents) > 0 {
+		t.Errorf("got %v torrents from a cancelled search", len(torrents))
+	}
+
+	if recorded := stats.List(); len(recorded) > 0 {
+		t.Errorf("got %v, want no statistics for an interrupted search", recorded)
+	}
+}
+
 func TestGetMirrors(t *testing.T) {
 	var scraper MirrorScraper
 
@@ -177,3 +230,65 @@ func TestParseLoadTime(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTorrentsRecordsOnce(t *testing.T) {
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	dead := server.URL
+	server.Close()
+
+	stats := NewMirrorStats()
+
+	// The fallback mirror is skipped, so that the test does not reach the network.
+	for i := 0; i < DefaultMirrorMaxFailures; i++ {
+		stats.Record(FallbackMirror().URL, 0, 0, errors.New("down"))
+	}
+
+	mirrors := []Mirror{
+		{URL: dead + "/up", Status: true},
+		{URL: dead + "/down", Status: false},
+	}
+
+	mirrorScraper := NewMirrorScraper("", MirrorFilters{Preferred: dead + "/up"})
+
+	if _, _, err := mirrorScraper.getTorrents(mirrors, "ubuntu", true, stats, nil); err == nil {
+		t.Errorf("expected an error when all mirrors are down")
+	}
+
+	for _, mirror := range mirrors {
+		if failures := stats.Get(mirror.URL).ConsecutiveFailures; failures != 1 {
+			t.Errorf("%v: got %v failures, want 1", mirror.URL, failures)
+		}
+	}
+
+	page, err := ioutil.ReadFile("../../test_samples/piratebay_search.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A mirror which only serves one of the search URL formats is still working.
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if strings.HasPrefix(r.URL.Path, "/search/") {
+			w.Write(page)
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	mirrors = []Mirror{{URL: server.URL, Status: true}}
+	mirrorScraper = NewMirrorScraper("", MirrorFilters{Preferred: server.URL})
+
+	for i := 0; i < DefaultMirrorMaxFailures+1; i++ {
+
+		if _, torrents, err := mirrorScraper.getTorrents(mirrors, "avengers", true, stats, nil); err != nil || len(torrents) == 0 {
+			t.Fatalf("search %v: got %v torrents and error %v", i+1, len(torrents), err)
+		}
+	}
+
+	if s := stats.Get(server.URL); s.Successes != DefaultMirrorMaxFailures+1 || s.ConsecutiveFailures != 0 {
+		t.Errorf("got %v successes and %v failures, want every search to be recorded as a success", s.Successes, s.ConsecutiveFailures)
+	}
+}
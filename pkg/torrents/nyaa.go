@@ -0,0 +1,122 @@
+package torrents
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+const defaultNyaaURL string = "https://nyaa.si"
+
+// defaultNyaaCategory is the category of English-translated anime.
+const defaultNyaaCategory string = "1_2"
+
+const nyaaTimeout = 10 * time.Second
+
+// NyaaConfig holds the configuration of the Nyaa anime source.
+type NyaaConfig struct {
+	URL      string `toml:"url"`
+	Category string `toml:"category"`
+}
+
+// nyaaSource searches a Nyaa-style tracker through the RSS feed of its search results.
+type nyaaSource struct {
+	url      string
+	category string
+	ctx      context.Context
+}
+
+func init() {
+	RegisterSource("nyaa", newNyaaSource)
+}
+
+func newNyaaSource(filters SearchFilters) (Source, error) {
+
+	source := nyaaSource{filters.Sources.Nyaa.URL, filters.Sources.Nyaa.Category, filters.Context}
+
+	if source.url == "" {
+		source.url = defaultNyaaURL
+	}
+	if source.category == "" {
+		source.category = defaultNyaaCategory
+	}
+
+	return &source, nil
+}
+
+func (s *nyaaSource) Name() string {
+	return "nyaa"
+}
+
+// SearchURL returns the URL of the RSS feed with the search results for the given query.
+func (s *nyaaSource) SearchURL(query string) (string, error) {
+
+	searchURL, err := url.Parse(s.url)
+
+	if err != nil {
+		return "", err
+	}
+
+	queryBuilder := searchURL.Query()
+	queryBuilder.Set("page", "rss")
+	queryBuilder.Set("q", utils.NormalizeQuery(query))
+	queryBuilder.Set("c", s.category)
+	queryBuilder.Set("f", "0")
+	searchURL.RawQuery = queryBuilder.Encode()
+
+	return searchURL.String(), nil
+}
+
+func (s *nyaaSource) Search(query string) ([]Torrent, error) {
+
+	searchURL, err := s.SearchURL(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := utils.HTTPClient{
+		Timeout:  nyaaTimeout,
+		Category: utils.TorrentRequests,
+		Context:  s.ctx,
+	}
+
+	var feed FeedDocument
+
+	if err = client.GetXML(searchURL, &feed); err != nil {
+		return nil, err
+	}
+
+	var trnts []Torrent
+
+	for _, item := range feed.Channel.Items {
+
+		torrent := item.GetTorrent()
+
+		// The link of an item points to the .torrent file, while its guid points to the torrent's page.
+		torrent.MirrorURL, torrent.TorrentURL = splitTorrentURL(item.GUID)
+		torrent.Uploader = extractReleaseGroup(item.Title)
+
+		trnts = append(trnts, torrent)
+	}
+
+	return trnts, nil
+}
+
+// extractReleaseGroup returns the name of the group in the square brackets at the start
+// of an anime release's name, such as "[SubsPlease] Title - 01 [1080p]".
+func extractReleaseGroup(title string) string {
+
+	r, _ := regexp.Compile(`^\s*\[([^\]]+)\]`)
+	m := r.FindStringSubmatch(title)
+
+	if len(m) > 0 {
+		return strings.TrimSpace(m[1])
+	}
+
+	return ""
+}
@@ -0,0 +1,96 @@
+package torrents
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNyaaSearchURL(t *testing.T) {
+
+	source, _ := NewSource("nyaa", SearchFilters{})
+
+	s, err := source.(*nyaaSource).SearchURL("One Piece - 1071")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "https://nyaa.si?c=1_2&f=0&page=rss&q=one+piece+1071"
+
+	if s != want {
+		t.Errorf("got %v, want %v", s, want)
+	}
+}
+
+func TestNyaaSearch(t *testing.T) {
+
+	server := sampleServer(sampleRoute{
+		path:   "/",
+		sample: "nyaa.xml",
+		match: func(query url.Values) bool {
+
+			if query.Get("page") != "rss" || query.Get("c") != "1_4" {
+				t.Errorf("unexpected query: %v", query.Encode())
+			}
+
+			return true
+		},
+	})
+	defer server.Close()
+
+	filters := SearchFilters{
+		Sources:     SourceConfig{Enabled: []string{"nyaa"}, Nyaa: NyaaConfig{URL: server.URL, Category: "1_4"}},
+		SearchTerms: []string{"One Piece 1071"},
+	}
+
+	torrents, err := filters.SearchTorrents("One Piece 1071")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torrents) != 3 {
+		t.Fatalf("got %v torrents, want 3", len(torrents))
+	}
+
+	tor := torrents[0]
+
+	if tor.Title != "[SubsPlease] One Piece - 1071 (1080p) [4D2A8F1B].mkv" || tor.Size != 1503239 || tor.Seeders != 2317 ||
+		tor.Leeches != 40 || !tor.VerifiedUploader || tor.Uploader != "SubsPlease" || tor.VideoQuality != High ||
+		tor.FullURL() != "https://nyaa.si/view/1702113" || !strings.Contains(tor.Magnet, "xt=urn:btih:1f8f5e47c8c3a8b5f0b4c1d4e6a2b3c9d8e7f6a5") {
+
+		t.Errorf("got %v", tor)
+	}
+
+	if tor := torrents[2]; tor.Uploader != "Erai-raws" || tor.VerifiedUploader || tor.VideoQuality != Medium {
+		t.Errorf("got %v", tor)
+	}
+
+	if filtered := filters.FilterTorrents(torrents); len(filtered) != 2 {
+		t.Errorf("expected only the releases of episode 1071, got %v", filtered)
+	}
+}
+
+func TestExtractReleaseGroup(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out string
+	}{
+		{"[SubsPlease] One Piece - 1071 (1080p) [4D2A8F1B].mkv", "SubsPlease"},
+		{" [Erai-raws] One Piece - 1071 [720p]", "Erai-raws"},
+		{"One.Piece.1071.1080p.WEB.x264", ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			s := extractReleaseGroup(tt.in)
+
+			if s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
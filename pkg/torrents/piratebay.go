@@ -1,6 +1,7 @@
 package torrents
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -26,12 +27,22 @@ type PirateBayScaper interface {
 	APISearchURLs(query string) []string
 	Search(query string) ([]Torrent, error)
 	SearchTimeout(query string, timeout time.Duration) ([]Torrent, error)
+	SearchContext(ctx context.Context, query string) ([]Torrent, error)
 	SearchVideoTorrents(query string, filters SearchFilters) ([]Torrent, error)
 	ParseSearchPage(doc *goquery.Document) []Torrent
+	SetTimezone(loc *time.Location)
 }
 
+// DefaultSearchOrder is the ordering of the search results on Pirate Bay mirrors, when none is configured.
+const DefaultSearchOrder = 99
+
 type pirateBayScaper struct {
-	url *url.URL
+	url        *url.URL
+	location   *time.Location
+	pages      int
+	maxResults int
+	category   int
+	orderBy    int
 }
 
 // NewScraper initializes a new PirateBay scapper from a mirror url.
@@ -44,6 +55,9 @@ func NewScraper(mirrorURL string) PirateBayScaper {
 
 	var scraper pirateBayScaper
 	scraper.url = URL
+	scraper.location = time.UTC
+	scraper.pages = 1
+	scraper.orderBy = DefaultSearchOrder
 	return &scraper
 }
 
@@ -63,27 +77,103 @@ func FindScraper(query string) (PirateBayScaper, error) {
 	return scraper, nil
 }
 
+// pirateBaySource searches either the mirror specified in the filters or all available Pirate Bay mirrors.
+type pirateBaySource struct {
+	ctx             context.Context
+	mirrorURL       string
+	proxySourceURL  string
+	mirrorFilters   MirrorFilters
+	mirrorStatsPath string
+	proxyListCache  string
+}
+
+func init() {
+	RegisterSource("piratebay", newPirateBaySource)
+}
+
+func newPirateBaySource(filters SearchFilters) (Source, error) {
+
+	return &pirateBaySource{
+		ctx:             filters.searchContext(),
+		mirrorURL:       filters.MirrorURL,
+		proxySourceURL:  filters.ProxyListURL,
+		mirrorFilters:   filters.MirrorFilters,
+		mirrorStatsPath: filters.MirrorStatsPath,
+		proxyListCache:  filters.ProxyListCache,
+	}, nil
+}
+
+func (s *pirateBaySource) Name() string {
+	return "piratebay"
+}
+
+func (s *pirateBaySource) Search(query string) ([]Torrent, error) {
+
+	if s.mirrorURL != "" {
+
+		// A specific mirror was specified.
+		scraper := s.mirrorFilters.Scraper(s.mirrorURL)
+		return scraper.SearchContext(s.ctx, query)
+	}
+
+	// A specific mirror wasn't specified.
+	mirrorScraper := NewMirrorScraper(s.proxySourceURL, s.mirrorFilters)
+	mirrorScraper.SetStatsPath(s.mirrorStatsPath)
+	mirrorScraper.SetCachePath(s.proxyListCache)
+	mirrorScraper.SetContext(s.ctx)
+
+	return mirrorScraper.GetTorrents(query)
+}
+
 func (s *pirateBayScaper) URL() string {
 	return s.url.String()
 }
 
+// SetTimezone sets the timezone in which the mirror displays upload times.
+func (s *pirateBayScaper) SetTimezone(loc *time.Location) {
+	s.location = loc
+}
+
+// order returns the ordering of the search results, defaulting to DefaultSearchOrder.
+func (s *pirateBayScaper) order() int {
+
+	if s.orderBy == 0 {
+		return DefaultSearchOrder
+	}
+
+	return s.orderBy
+}
+
 func (s *pirateBayScaper) SearchURLs(query string) []string {
 
+	return s.searchURLs(query, 0)
+}
+
+// searchURLs returns the URLs of the given page of the search results, in each of the formats used by mirrors.
+func (s *pirateBayScaper) searchURLs(query string, page int) []string {
+
 	query = utils.NormalizeQuery(query)
 
+	orderBy := strconv.Itoa(s.order())
+	category := strconv.Itoa(s.category)
+
 	var urls []string
 
 	searchURL, _ := url.Parse(s.URL())
 
 	// First url (legacy)
-	searchURL.Path = path.Join("/search", query)
+	if page == 0 && s.order() == DefaultSearchOrder && s.category == 0 {
+		searchURL.Path = path.Join("/search", query)
+	} else {
+		searchURL.Path = path.Join("/search", query, strconv.Itoa(page), orderBy, category)
+	}
 	urls = append(urls, searchURL.String())
 
 	// Second url (piratesbaycc.com)
 	searchURL.Path = "/search.php"
 	queryBuilder := searchURL.Query()
-	queryBuilder.Set("orderby", "99")
-	queryBuilder.Set("page", "0")
+	queryBuilder.Set("orderby", orderBy)
+	queryBuilder.Set("page", strconv.Itoa(page))
 	queryBuilder.Set("q", url.QueryEscape(query))
 	searchURL.RawQuery = queryBuilder.Encode()
 	urls = append(urls, searchURL.String())
@@ -91,9 +181,9 @@ func (s *pirateBayScaper) SearchURLs(query string) []string {
 	// third url (knaben)
 	searchURL.Path = "/s/"
 	queryBuilder = searchURL.Query()
-	queryBuilder.Set("orderby", "99")
-	queryBuilder.Set("page", "0")
-	queryBuilder.Set("category", "0")
+	queryBuilder.Set("orderby", orderBy)
+	queryBuilder.Set("page", strconv.Itoa(page))
+	queryBuilder.Set("category", category)
 	queryBuilder.Set("q", url.QueryEscape(query))
 	searchURL.RawQuery = queryBuilder.Encode()
 	urls = append(urls, searchURL.String())
@@ -114,9 +204,12 @@ func (s *pirateBayScaper) APISearchURLs(query string) []string {
 	searchURL.RawQuery = "url=/q.php?q=" + url.QueryEscape(query)
 	urls = append(urls, searchURL.String())
 
-	// second api
+	// second api, which also accepts a category
 	searchURL.Path = "/apibay/q.php"
 	searchURL.RawQuery = "q=" + url.QueryEscape(query)
+	if s.category != 0 {
+		searchURL.RawQuery += "&cat=" + strconv.Itoa(s.category)
+	}
 	urls = append(urls, searchURL.String())
 
 	return urls
@@ -124,12 +217,19 @@ func (s *pirateBayScaper) APISearchURLs(query string) []string {
 
 func (s *pirateBayScaper) SearchTimeout(query string, timeout time.Duration) ([]Torrent, error) {
 
-	return s.search(query, timeout)
+	return s.search(context.Background(), query, timeout)
 }
 
 func (s *pirateBayScaper) Search(query string) ([]Torrent, error) {
 
-	return s.search(query, 0)
+	return s.search(context.Background(), query, 0)
+}
+
+// SearchContext searches the mirror until the given context is cancelled or expires,
+// in which case the outstanding requests are aborted.
+func (s *pirateBayScaper) SearchContext(ctx context.Context, query string) ([]Torrent, error) {
+
+	return s.search(ctx, query, 0)
 }
 
 func (s *pirateBayScaper) ParseSearchPage(doc *goquery.Document) []Torrent {
@@ -169,7 +269,7 @@ func (s *pirateBayScaper) ParseSearchPage(doc *goquery.Document) []Torrent {
 		uploader := cells[1].Find(".detDesc > a.detDesc").Text()
 
 		size := extractSize(description)
-		uploadTime := extractUploadTime(description)
+		uploadTime := extractUploadTime(description, s.location)
 		quality := extractVideoQuality(title)
 		releaseType := ExtractVideoRelease(title)
 
@@ -238,13 +338,74 @@ func (s *pirateBayScaper) SearchVideoTorrentsTimeout(query string, filters Searc
 	return perQualitySlice, nil
 }
 
+// GetNextPageURL returns the URL of the next page of the search results, or an empty string if it is the last page.
 func (s *pirateBayScaper) GetNextPageURL(doc *goquery.Document) string {
 
 	a := doc.Find("img[alt='Next']").Parent()
 
-	relative, _ := a.Attr("href")
+	relative, exists := a.Attr("href")
 
-	return path.Join(s.URL(), relative)
+	if !exists || relative == "" {
+		return ""
+	}
+
+	next, err := url.Parse(relative)
+
+	if err != nil {
+		return ""
+	} else if next.IsAbs() {
+		return next.String()
+	}
+
+	next.Path = path.Join("/", next.Path)
+
+	return strings.TrimSuffix(s.URL(), "/") + next.String()
+}
+
+// searchPages reads the pages of the search results in one of the formats used by mirrors, following the pagination
+// of the mirror until the configured number of pages or results has been read, or a page has no new results.
+func (s *pirateBayScaper) searchPages(client utils.HTTPClient, query string, format int) ([]Torrent, error) {
+
+	var torrents []Torrent
+
+	pageURL := s.searchURLs(query, 0)[format]
+
+	for page := 0; page == 0 || page < s.pages; page++ {
+
+		pageURL = strings.Replace(pageURL, "%2B", "+", -1)
+
+		if os.Getenv("GOIRATE_DEBUG") == "true" {
+			log.Printf("Search url: %s\n", pageURL)
+		}
+
+		doc, err := client.Get(pageURL)
+
+		if err != nil && page == 0 {
+			return nil, err
+		} else if err != nil {
+
+			// The results of the previous pages are still usable.
+			if os.Getenv("GOIRATE_DEBUG") == "true" {
+				log.Printf("failed to read page %d of %v: %v\n", page, s.URL(), err)
+			}
+
+			break
+		}
+
+		previous := len(torrents)
+		torrents = DeduplicateTorrents(append(torrents, s.ParseSearchPage(doc)...))
+
+		// Mirrors that ignore the page respond with the same results again.
+		if len(torrents) == previous || (s.maxResults > 0 && len(torrents) >= s.maxResults) {
+			break
+		}
+
+		if pageURL = s.GetNextPageURL(doc); pageURL == "" {
+			pageURL = s.searchURLs(query, page+1)[format]
+		}
+	}
+
+	return torrents, nil
 }
 
 func extractSize(description string) int64 {
@@ -279,85 +440,94 @@ func extractSize(description string) int64 {
 	return 0.0
 }
 
-func extractUploadTime(description string) time.Time {
+// extractUploadTime parses the upload time from the description of a torrent in the search results,
+// which mirrors display in their own timezone.
+func extractUploadTime(description string, loc *time.Location) time.Time {
 
-	/*
-		First check the MM-DD HH:mm format
-	*/
-	r, _ := regexp.Compile(`Uploaded\s*(\d\d)-(\d\d)\s*(\d\d):(\d\d)`)
-	m := r.FindStringSubmatch(description)
+	if loc == nil {
+		loc = time.UTC
+	}
 
-	if len(m) > 0 {
-		day, _ := strconv.Atoi(m[2])
-		month, _ := strconv.Atoi(m[1])
-		hour, _ := strconv.Atoi(m[3])
-		minute, _ := strconv.Atoi(m[4])
-		year := time.Now().Year()
+	now := time.Now().In(loc)
+
+	r := regexp.MustCompile(`(?i)Uploaded\s*([^,]*)`)
+	m := r.FindStringSubmatch(strings.Replace(description, "\u00a0", " ", -1))
+
+	if len(m) == 0 {
+		return time.Time{}
+	}
 
-		return time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+	uploaded := strings.TrimSpace(m[1])
+
+	match := func(pattern string) []int {
+
+		m := regexp.MustCompile(`(?i)^` + pattern + `$`).FindStringSubmatch(uploaded)
+
+		if m == nil {
+			return nil
+		}
+
+		numbers := make([]int, len(m))
+		for i := 1; i < len(m); i++ {
+			numbers[i], _ = strconv.Atoi(m[i])
+		}
+
+		return numbers
 	}
 
 	/*
-		Next check the MM-DD YYYY format
+		Relative times, such as 50 mins ago
 	*/
-	r, _ = regexp.Compile(`Uploaded\s*(\d\d)-(\d\d)\s*(\d{4})`)
-	m = r.FindStringSubmatch(description)
-
-	if len(m) > 0 {
-		day, _ := strconv.Atoi(m[2])
-		month, _ := strconv.Atoi(m[1])
-		hour := 0
-		minute := 0
-		year, _ := strconv.Atoi(m[3])
+	if n := match(`(\d+)\s*mins?\s*ago`); n != nil {
+		return now.Add(time.Duration(-n[1]) * time.Minute)
+	}
 
-		return time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+	if n := match(`(\d+)\s*hours?\s*ago`); n != nil {
+		return now.Add(time.Duration(-n[1]) * time.Hour)
 	}
 
 	/*
-		Check the Today YYYY format
+		The Today HH:mm and Y-day HH:mm formats
 	*/
-	r, _ = regexp.Compile(`Uploaded\s*Today\s*(\d\d):(\d\d)`)
-	m = r.FindStringSubmatch(description)
+	if n := match(`Today\s*(\d\d):(\d\d)`); n != nil {
+		return time.Date(now.Year(), now.Month(), now.Day(), n[1], n[2], 0, 0, loc)
+	}
 
-	if len(m) > 0 {
-		day := time.Now().Day()
-		month := time.Now().Month()
-		hour, _ := strconv.Atoi(m[1])
-		minute, _ := strconv.Atoi(m[2])
-		year := time.Now().Year()
+	if n := match(`Y-day\s*(\d\d):(\d\d)`); n != nil {
+		yday := now.AddDate(0, 0, -1)
+		return time.Date(yday.Year(), yday.Month(), yday.Day(), n[1], n[2], 0, 0, loc)
+	}
 
-		return time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+	/*
+		The YYYY-MM-DD and YYYY-MM-DD HH:mm formats
+	*/
+	if n := match(`(\d{4})-(\d\d)-(\d\d)(?:\s*(\d\d):(\d\d)(?::(\d\d))?)?`); n != nil {
+		return time.Date(n[1], time.Month(n[2]), n[3], n[4], n[5], n[6], 0, loc)
 	}
 
 	/*
-		Finally, check the Y-day YYYY format
+		The MM-DD HH:mm format, which is used for uploads of the current year
 	*/
-	r, _ = regexp.Compile(`Uploaded\s*Y-day\s*(\d\d):(\d\d)`)
-	m = r.FindStringSubmatch(description)
+	if n := match(`(\d\d)-(\d\d)\s*(\d\d):(\d\d)`); n != nil {
 
-	if len(m) > 0 {
-		yday := time.Now().AddDate(0, 0, -1)
+		t := time.Date(now.Year(), time.Month(n[1]), n[2], n[3], n[4], 0, 0, loc)
 
-		day := yday.Day()
-		month := yday.Month()
-		hour, _ := strconv.Atoi(m[1])
-		minute, _ := strconv.Atoi(m[2])
-		year := yday.Year()
+		if t.After(now.AddDate(0, 0, 1)) {
+			// Dates past today are from the end of the previous year.
+			t = t.AddDate(-1, 0, 0)
+		}
 
-		return time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+		return t
 	}
 
-	r, _ = regexp.Compile(`Uploaded\s*(\d+)\s*mins\s*ago`)
-	m = r.FindStringSubmatch(description)
-
-	if len(m) == 0 {
-		return time.Time{}
+	/*
+		The MM-DD YYYY format
+	*/
+	if n := match(`(\d\d)-(\d\d)\s*(\d{4})`); n != nil {
+		return time.Date(n[3], time.Month(n[1]), n[2], 0, 0, 0, 0, loc)
 	}
 
-	minutes, _ := strconv.Atoi(m[1])
-	minutesAgo := time.Now().Add(time.Duration(-minutes) * time.Minute)
-
-	return minutesAgo
+	return time.Time{}
 }
 
 func extractVideoQuality(title string) VideoQuality {
@@ -389,44 +559,42 @@ func extractVideoQuality(title string) VideoQuality {
 	return quality
 }
 
-func (s *pirateBayScaper) search(query string, timeout time.Duration) ([]Torrent, error) {
+// search queries all of the mirror's search URLs, with the timeout applying to each request.
+func (s *pirateBayScaper) search(ctx context.Context, query string, timeout time.Duration) ([]Torrent, error) {
 
-	torrentsChannel := make(chan []Torrent)
-	errorChannel := make(chan error)
+	type searchResponse struct {
+		torrents []Torrent
+		err      error
+	}
 
-	// First go through the search URLs for HTML responses.
 	searchURLs := s.SearchURLs(query)
-	for _, searchURL := range searchURLs {
-
-		searchURLformatted := strings.Replace(searchURL, "%2B", "+", -1)
+	apiSearchURLs := s.APISearchURLs(query)
 
-		if os.Getenv("GOIRATE_DEBUG") == "true" {
-			log.Printf("Search url: %s\n", searchURLformatted)
-		}
+	totalSearchCount := len(searchURLs) + len(apiSearchURLs)
 
-		go func() {
+	// The channel is buffered, so that the requests can finish even after the search has been abandoned.
+	responses := make(chan searchResponse, totalSearchCount)
 
-			client := utils.HTTPClient{
-				Timeout: timeout,
-			}
+	client := utils.HTTPClient{
+		Timeout:  timeout,
+		Category: utils.TorrentRequests,
+		Context:  ctx,
+	}
 
-			doc, err := client.Get(searchURLformatted)
+	// First go through the search URLs for HTML responses, each following its own pagination.
+	for format := range searchURLs {
 
-			if err == nil {
+		format := format
 
-				torrentsChannel <- s.ParseSearchPage(doc)
-				errorChannel <- nil
+		go func() {
 
-			} else {
+			torrents, err := s.searchPages(client, query, format)
 
-				torrentsChannel <- nil
-				errorChannel <- err
-			}
+			responses <- searchResponse{torrents, err}
 		}()
 	}
 
 	// Then go through the URLs for JSON responses.
-	apiSearchURLs := s.APISearchURLs(query)
 	for _, searchURL := range apiSearchURLs {
 
 		searchURLformatted := strings.Replace(searchURL, "%2B", "+", -1)
@@ -437,10 +605,6 @@ func (s *pirateBayScaper) search(query string, timeout time.Duration) ([]Torrent
 
 		go func() {
 
-			client := utils.HTTPClient{
-				Timeout: timeout,
-			}
-
 			var apiResponse PirateBayAPIResponse
 
 			err := client.GetJSON(searchURLformatted, &apiResponse)
@@ -449,13 +613,11 @@ func (s *pirateBayScaper) search(query string, timeout time.Duration) ([]Torrent
 
 				mirrorURL, _ := url.Parse(searchURLformatted)
 
-				torrentsChannel <- apiResponse.GetTorrents(mirrorURL)
-				errorChannel <- nil
+				responses <- searchResponse{apiResponse.GetTorrents(mirrorURL), nil}
 
 			} else {
 
-				torrentsChannel <- nil
-				errorChannel <- err
+				responses <- searchResponse{nil, err}
 			}
 		}()
 	}
@@ -463,42 +625,32 @@ func (s *pirateBayScaper) search(query string, timeout time.Duration) ([]Torrent
 	var allTorrents []Torrent
 	var allError error
 
-	totalSearchCount := len(searchURLs) + len(apiSearchURLs)
-
+collect:
 	for i := 0; i < totalSearchCount; i++ {
 
-		torrents := <-torrentsChannel
-		err := <-errorChannel
-
-		if err != nil {
-			allError = err
-		}
-
-		if torrents != nil {
-			allTorrents = append(allTorrents, torrents...)
-		}
-	}
+		select {
 
-	// Remove duplicates.
-	var duplicatesFiltered []Torrent
+		case resp := <-responses:
 
-	contains := func(title string) bool {
-		for _, t := range duplicatesFiltered {
-			if t.Title == title {
-				return true
+			if resp.err != nil {
+				allError = resp.err
 			}
-		}
-		return false
-	}
 
-	for _, torrent := range allTorrents {
+			if resp.torrents != nil {
+				allTorrents = append(allTorrents, resp.torrents...)
+			}
 
-		if !contains(torrent.Title) {
+		case <-ctx.Done():
 
-			duplicatesFiltered = append(duplicatesFiltered, torrent)
+			// Keep the results of the requests that completed in time.
+			allError = ctx.Err()
+			break collect
 		}
 	}
 
+	// Remove duplicates, since the HTML pages and the API usually return the same torrents.
+	duplicatesFiltered := DeduplicateTorrents(allTorrents)
+
 	// Sort by seeders.
 	sort.Sort(sortBySeeds(duplicatesFiltered))
 
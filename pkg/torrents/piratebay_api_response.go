@@ -27,17 +27,6 @@ type PirateBayAPIResponseTorrent struct {
 // PirateBayAPIResponse represents the response returned by the PirateBay API.
 type PirateBayAPIResponse []PirateBayAPIResponseTorrent
 
-var trackers = []string{
-	"udp://tracker.coppersurfer.tk:6969/announce",
-	"udp://9.rarbg.to:2920/announce",
-	"udp://tracker.opentrackr.org:1337",
-	"udp://tracker.internetwarriors.net:1337/announce",
-	"udp://tracker.leechers-paradise.org:6969/announce",
-	"udp://tracker.coppersurfer.tk:6969/announce",
-	"udp://tracker.pirateparty.gr:6969/announce",
-	"udp://tracker.cyberia.is:6969/announce",
-}
-
 // GetTorrents converts the response from the PirateBay API into a list of torrents.
 func (response PirateBayAPIResponse) GetTorrents(mirrorURL *url.URL) []Torrent {
 
@@ -68,6 +57,7 @@ func (response PirateBayAPIResponse) GetTorrents(mirrorURL *url.URL) []Torrent {
 			Magnet:           obj.getMagnetLink(),
 			UploadTime:       time.Unix(addedTimeInt, 0),
 			Uploader:         obj.Username,
+			IMDbID:           obj.IMDB,
 		}
 
 		trnts = append(trnts, torrent)
@@ -78,19 +68,5 @@ func (response PirateBayAPIResponse) GetTorrents(mirrorURL *url.URL) []Torrent {
 
 func (torrent PirateBayAPIResponseTorrent) getMagnetLink() string {
 
-	title := strings.ReplaceAll(torrent.Name, " ", "+")
-	title = strings.Replace(title, "%2B", "+", -1)
-
-	magnet, _ := url.Parse("magnet:")
-	query := magnet.Query()
-	query.Add("dn", title)
-
-	for _, tracker := range trackers {
-
-		query.Add("tr", tracker)
-	}
-
-	magnet.RawQuery = query.Encode() + "&xt=urn:btih:" + torrent.InfoHash
-
-	return magnet.String()
+	return NewMagnet(torrent.InfoHash, torrent.Name).String()
 }
@@ -1,12 +1,20 @@
 package torrents
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"gitlab.com/haath/goirate/pkg/utils"
 )
 
 var urlTests = []struct {
@@ -91,7 +99,7 @@ func TestParseSearchPage(t *testing.T) {
 	}
 
 	u, _ := url.Parse("localhost")
-	scraper := pirateBayScaper{u}
+	scraper := pirateBayScaper{url: u}
 
 	torrents := scraper.ParseSearchPage(doc)
 
@@ -157,10 +165,16 @@ func TestExtractUploadTime(t *testing.T) {
 		{"Uploaded Today 08:05, Size 1.62 GiB, ULedbyAnonymous", time.Now().Year(), time.Now().Month(), time.Now().Day(), 8, 5},
 		{"Uploaded Y-day 08:05, Size 1.62 GiB, ULedbyAnonymous", yday.Year(), yday.Month(), yday.Day(), 8, 5},
 		{"Uploaded 50 mins ago, Size 1.62 GiB, ULedbyAnonymous", minago.Year(), minago.Month(), minago.Day(), minago.Hour(), minago.Minute()},
+		{"Uploaded50minsago,Size1.62GiB,ULedbyAnonymous", minago.Year(), minago.Month(), minago.Day(), minago.Hour(), minago.Minute()},
+		{"Uploaded02-272014,Size58.35MiB,ULedbygnv65", 2014, time.February, 27, 0, 0},
+		{"Uploaded\u00a0Y-day\u00a008:05, Size 1.62 GiB", yday.Year(), yday.Month(), yday.Day(), 8, 5},
+		{"Uploaded 2019-04-29 04:41, Size 3.58 GiB, ULed by makintos13", 2019, time.April, 29, 4, 41},
+		{"Uploaded 2014-02-27, Size 58.35 MiB, ULed by gnv65", 2014, time.February, 27, 0, 0},
+		{"Size 58.35 MiB, ULed by gnv65", 1, time.January, 1, 0, 0},
 	}
 	for _, tt := range table {
 		t.Run(tt.in, func(t *testing.T) {
-			s := extractUploadTime(tt.in)
+			s := extractUploadTime(tt.in, time.UTC)
 			if s.Year() != tt.year || s.Month() != tt.month || s.Day() != tt.day || s.Hour() != tt.hour || s.Minute() != tt.minute {
 				t.Errorf("got %v, want %v", s, tt)
 			}
@@ -168,6 +182,28 @@ func TestExtractUploadTime(t *testing.T) {
 	}
 }
 
+func TestExtractUploadTimeTimezone(t *testing.T) {
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	uploaded := extractUploadTime("Uploaded 02-27 2014 , Size 58.35 MiB", loc)
+	if want := time.Date(2014, time.February, 26, 22, 0, 0, 0, time.UTC); !uploaded.Equal(want) {
+		t.Errorf("got %v, want %v", uploaded.UTC(), want)
+	}
+
+	uploaded = extractUploadTime("Uploaded 2019-04-29 04:41, Size 3.58 GiB", loc)
+	if want := time.Date(2019, time.April, 29, 2, 41, 0, 0, time.UTC); !uploaded.Equal(want) {
+		t.Errorf("got %v, want %v", uploaded.UTC(), want)
+	}
+
+	// Dates without a year that would be in the future are from the previous year.
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 2)
+	uploaded = extractUploadTime(tomorrow.Format("Uploaded 01-02 15:04, Size 3.58 GiB"), loc)
+	if uploaded.Year() != tomorrow.Year()-1 || uploaded.After(time.Now()) {
+		t.Errorf("got %v, want a date in %v", uploaded, tomorrow.Year()-1)
+	}
+}
+
 func TestExtractVideoQuality(t *testing.T) {
 
 	table := []struct {
@@ -234,3 +270,145 @@ func TestSearchVideoTorrents(t *testing.T) {
 		t.Errorf("duplicate video torrent qualities: %v", torrents)
 	}
 }
+
+func TestSearchContext(t *testing.T) {
+
+	page, err := ioutil.ReadFile("../../test_samples/piratebay_search.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if strings.HasPrefix(r.URL.Path, "/search/") {
+			w.Write(page)
+			return
+		}
+
+		// Every other search URL hangs until the search is abandoned.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	scraper := NewScraper(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	torrents, err := scraper.SearchContext(ctx, "avengers")
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if len(torrents) == 0 {
+		t.Errorf("expected the results of the page that responded in time")
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("search took %v after its context expired", elapsed)
+	}
+}
+
+func TestSearchURLPages(t *testing.T) {
+
+	filters := MirrorFilters{Category: 200, OrderBy: 7}
+	s := filters.Scraper("https://pirateproxy.sh/").(*pirateBayScaper)
+
+	searchTests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"legacy", s.searchURLs("one two", 2)[0], "https://pirateproxy.sh/search/one%20two/2/7/200"},
+		{"search.php", s.searchURLs("one two", 2)[1], "https://pirateproxy.sh/search.php?orderby=7&page=2&q=one%2Btwo"},
+		{"knaben", s.searchURLs("one two", 2)[2], "https://pirateproxy.sh/s/?category=200&orderby=7&page=2&q=one%2Btwo"},
+		{"apibay", s.APISearchURLs("one two")[1], "https://pirateproxy.sh/apibay/q.php?q=one+two&cat=200"},
+		{"default", NewScraper("https://pirateproxy.sh/").SearchURLs("one two")[0], "https://pirateproxy.sh/search/one%20two"},
+	}
+
+	for _, tt := range searchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+
+	// Each page has two torrents, while the mirror responds to pages past the third one with the third one again.
+	searchPage := func(page int, next string) string {
+
+		html := `<table id="searchResult"><tbody>`
+
+		for i := 0; i < 2; i++ {
+			n := 2*page + i
+			html += fmt.Sprintf(`<tr><td></td><td><div class="detName"><a class="detLink" href="/torrent/%d">Debian %d</a></div>`+
+				`<a href="magnet:?xt=urn:btih:%040x">magnet</a><font class="detDesc">Size 1 GiB</font></td><td>10</td><td>1</td></tr>`, n, n, n+1)
+		}
+
+		html += `</tbody></table>`
+
+		if next != "" {
+			html += fmt.Sprintf(`<a href="%v"><img alt="Next"></a>`, next)
+		}
+
+		return html
+	}
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests++
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		switch {
+		case page == 0:
+			w.Write([]byte(searchPage(0, "/search.php?q=debian&page=1")))
+		case page > 2:
+			w.Write([]byte(searchPage(2, "")))
+		default:
+			w.Write([]byte(searchPage(page, "")))
+		}
+	}))
+	defer server.Close()
+
+	var tests = []struct {
+		pages      int
+		maxResults int
+		torrents   int
+		requests   int
+	}{
+		{1, 0, 2, 1},
+		{5, 0, 6, 4},
+		{5, 3, 4, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d pages, %d results", tt.pages, tt.maxResults), func(t *testing.T) {
+
+			requests = 0
+
+			filters := MirrorFilters{Pages: tt.pages, MaxResults: tt.maxResults}
+			scraper := filters.Scraper(server.URL).(*pirateBayScaper)
+
+			torrents, err := scraper.searchPages(utils.HTTPClient{}, "debian", 1)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(torrents) != tt.torrents {
+				t.Errorf("got %v torrents, want %v", len(torrents), tt.torrents)
+			}
+			if requests != tt.requests {
+				t.Errorf("got %v requests, want %v", requests, tt.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package torrents
+
+import (
+	"strings"
+)
+
+// ReleaseFilters holds filters regarding the encoding of a release, as it is parsed from the torrent's title.
+// The include filters only accept releases whose title mentions one of the given values,
+// while the exclude filters reject releases that mention any of them.
+type ReleaseFilters struct {
+	Codecs          []string `long:"codec" description:"Only consider torrents with one of these video codecs (x264, x265, AV1, VP9, XviD)." toml:"codecs"`
+	ExcludeCodecs   []string `long:"exclude-codec" description:"Avoid torrents with these video codecs." toml:"exclude-codecs"`
+	HDR             []string `long:"hdr" description:"Only consider torrents with one of these HDR formats (HDR10, HDR10+, DV, HLG, or HDR for any of them)." toml:"hdr"`
+	ExcludeHDR      []string `long:"exclude-hdr" description:"Avoid torrents with these HDR formats (HDR10, HDR10+, DV, HLG, or HDR for any of them)." toml:"exclude-hdr"`
+	Audio           []string `long:"audio" description:"Only consider torrents with one of these audio formats (AAC, AC3, EAC3, DTS, DTS-HD, TrueHD, FLAC, Opus, MP3, LPCM, Atmos)." toml:"audio"`
+	ExcludeAudio    []string `long:"exclude-audio" description:"Avoid torrents with these audio formats." toml:"exclude-audio"`
+	Channels        []string `long:"channels" description:"Only consider torrents with one of these audio channel layouts (2.0, 5.1, 7.1)." toml:"channels"`
+	ExcludeChannels []string `long:"exclude-channels" description:"Avoid torrents with these audio channel layouts." toml:"exclude-channels"`
+}
+
+// IsOk returns true if the given release complies with the filters.
+func (f ReleaseFilters) IsOk(info ReleaseInfo) bool {
+
+	check := func(include []string, exclude []string, matches func(string) bool) bool {
+
+		anyMatch := func(values []string) bool {
+			for _, value := range values {
+				if matches(value) {
+					return true
+				}
+			}
+			return false
+		}
+
+		return (len(include) == 0 || anyMatch(include)) && !anyMatch(exclude)
+	}
+
+	return check(f.Codecs, f.ExcludeCodecs, info.matchesCodec) &&
+		check(f.HDR, f.ExcludeHDR, info.matchesHDR) &&
+		check(f.Audio, f.ExcludeAudio, info.matchesAudio) &&
+		check(f.Channels, f.ExcludeChannels, info.matchesChannels)
+}
+
+// Merge returns the filters combined with the given ones, with the values of both lists added together.
+func (f ReleaseFilters) Merge(other ReleaseFilters) ReleaseFilters {
+
+	merge := func(a []string, b []string) []string {
+
+		if len(a)+len(b) == 0 {
+			return nil
+		}
+
+		return append(append([]string{}, a...), b...)
+	}
+
+	return ReleaseFilters{
+		Codecs:          merge(f.Codecs, other.Codecs),
+		ExcludeCodecs:   merge(f.ExcludeCodecs, other.ExcludeCodecs),
+		HDR:             merge(f.HDR, other.HDR),
+		ExcludeHDR:      merge(f.ExcludeHDR, other.ExcludeHDR),
+		Audio:           merge(f.Audio, other.Audio),
+		ExcludeAudio:    merge(f.ExcludeAudio, other.ExcludeAudio),
+		Channels:        merge(f.Channels, other.Channels),
+		ExcludeChannels: merge(f.ExcludeChannels, other.ExcludeChannels),
+	}
+}
+
+// matchesCodec accepts any of the names of a codec, such as HEVC, h265 or x265.
+func (info ReleaseInfo) matchesCodec(codec string) bool {
+
+	if known := firstPatternValue(videoCodecPatterns, codec); known != "" {
+		codec = known
+	}
+
+	return info.VideoCodec != "" && strings.EqualFold(string(info.VideoCodec), codec)
+}
+
+// matchesHDR accepts HDR for any of the HDR formats, and SDR for releases without one.
+func (info ReleaseInfo) matchesHDR(format string) bool {
+
+	switch strings.ToUpper(format) {
+
+	case "HDR":
+		return len(info.HDR) > 0
+
+	case "SDR":
+		return len(info.HDR) == 0
+	}
+
+	if known := firstPatternValue(hdrPatterns, format); known != "" {
+		format = known
+	}
+
+	for _, hdr := range info.HDR {
+		if strings.EqualFold(string(hdr), format) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesAudio accepts Atmos, or a codec which also matches the codecs of its family, so that DTS also matches DTS-HD.
+func (info ReleaseInfo) matchesAudio(audio string) bool {
+
+	if strings.EqualFold(audio, "atmos") {
+		return info.Atmos
+	}
+
+	if known := firstPatternValue(audioCodecPatterns, audio); known != "" {
+		audio = known
+	}
+
+	return info.AudioCodec != "" && strings.HasPrefix(strings.ToLower(string(info.AudioCodec)), strings.ToLower(audio))
+}
+
+func (info ReleaseInfo) matchesChannels(channels string) bool {
+
+	return info.AudioChannels != "" && info.AudioChannels == strings.TrimSpace(channels)
+}
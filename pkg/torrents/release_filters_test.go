@@ -0,0 +1,78 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReleaseFiltersIsOk(t *testing.T) {
+
+	dolbyVision := "Some.Movie.2019.2160p.WEB-DL.DDP5.1.Atmos.DV.HEVC-GROUP"
+	hdr10 := "Some.Movie.2019.2160p.BluRay.TrueHD.7.1.HDR10.x265-GROUP"
+	sdr := "Some.Movie.2019.1080p.BluRay.DTS-HD.MA.5.1.x264-GROUP"
+	plain := "Some.Movie.2019.720p.WEB"
+
+	table := []struct {
+		filters ReleaseFilters
+		title   string
+		out     bool
+	}{
+		{ReleaseFilters{}, dolbyVision, true},
+		{ReleaseFilters{}, plain, true},
+		{ReleaseFilters{ExcludeHDR: []string{"DV"}}, dolbyVision, false},
+		{ReleaseFilters{ExcludeHDR: []string{"Dolby Vision"}}, dolbyVision, false},
+		{ReleaseFilters{ExcludeHDR: []string{"DV"}}, hdr10, true},
+		{ReleaseFilters{ExcludeHDR: []string{"HDR"}}, hdr10, false},
+		{ReleaseFilters{ExcludeHDR: []string{"HDR"}}, sdr, true},
+		{ReleaseFilters{HDR: []string{"SDR"}}, sdr, true},
+		{ReleaseFilters{HDR: []string{"HDR10"}}, hdr10, true},
+		{ReleaseFilters{HDR: []string{"HDR10"}}, sdr, false},
+		{ReleaseFilters{Codecs: []string{"HEVC"}}, dolbyVision, true},
+		{ReleaseFilters{Codecs: []string{"x265"}}, hdr10, true},
+		{ReleaseFilters{Codecs: []string{"x265", "AV1"}}, sdr, false},
+		{ReleaseFilters{Codecs: []string{"x265"}}, plain, false},
+		{ReleaseFilters{ExcludeCodecs: []string{"h265"}}, plain, true},
+		{ReleaseFilters{ExcludeCodecs: []string{"h265"}}, hdr10, false},
+		{ReleaseFilters{Audio: []string{"Atmos"}}, dolbyVision, true},
+		{ReleaseFilters{Audio: []string{"Atmos"}}, hdr10, false},
+		{ReleaseFilters{Audio: []string{"DTS"}}, sdr, true},
+		{ReleaseFilters{Audio: []string{"DD+"}}, dolbyVision, true},
+		{ReleaseFilters{ExcludeAudio: []string{"truehd"}}, hdr10, false},
+		{ReleaseFilters{ExcludeAudio: []string{"AAC"}}, hdr10, true},
+		{ReleaseFilters{Channels: []string{"5.1"}}, sdr, true},
+		{ReleaseFilters{Channels: []string{"5.1"}}, hdr10, false},
+		{ReleaseFilters{ExcludeChannels: []string{"7.1"}}, hdr10, false},
+		{ReleaseFilters{ExcludeChannels: []string{"7.1"}}, plain, true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			if ok := tt.filters.IsOk(ParseReleaseName(tt.title)); ok != tt.out {
+				t.Errorf("got %v, want %v for %+v", ok, tt.out, tt.filters)
+			}
+		})
+	}
+}
+
+func TestReleaseFiltersMerge(t *testing.T) {
+
+	a := ReleaseFilters{Codecs: []string{"x265"}, ExcludeHDR: []string{"DV"}}
+	b := ReleaseFilters{Codecs: []string{"AV1"}, ExcludeAudio: []string{"DTS"}}
+
+	merged := a.Merge(b)
+
+	expected := ReleaseFilters{
+		Codecs:       []string{"x265", "AV1"},
+		ExcludeHDR:   []string{"DV"},
+		ExcludeAudio: []string{"DTS"},
+	}
+
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("got %+v, want %+v", merged, expected)
+	}
+
+	if len(a.Codecs) != 1 {
+		t.Errorf("merge modified the original filters: %+v", a)
+	}
+}
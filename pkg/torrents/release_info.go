@@ -0,0 +1,510 @@
+package torrents
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VideoCodec defines the codec with which the video of a release is encoded.
+type VideoCodec string
+
+const (
+	// H264 is the AVC codec, usually encoded with x264.
+	H264 VideoCodec = "x264"
+
+	// H265 is the HEVC codec, usually encoded with x265.
+	H265 VideoCodec = "x265"
+
+	// AV1 is the AOMedia Video 1 codec.
+	AV1 VideoCodec = "AV1"
+
+	// VP9 is the VP9 codec, mostly found in web releases.
+	VP9 VideoCodec = "VP9"
+
+	// XviD represents the older MPEG-4 Part 2 codecs, XviD and DivX.
+	XviD VideoCodec = "XviD"
+)
+
+// HDRFormat defines a high dynamic range format of a release.
+type HDRFormat string
+
+const (
+	// HDR10 is the base HDR format, with static metadata.
+	HDR10 HDRFormat = "HDR10"
+
+	// HDR10Plus is HDR10 with dynamic metadata.
+	HDR10Plus HDRFormat = "HDR10+"
+
+	// DolbyVision is the proprietary HDR format from Dolby.
+	DolbyVision HDRFormat = "DV"
+
+	// HLG is the Hybrid Log-Gamma format, mostly used in broadcasts.
+	HLG HDRFormat = "HLG"
+)
+
+// AudioCodec defines the codec of the main audio track of a release.
+type AudioCodec string
+
+const (
+	// AAC is the Advanced Audio Coding codec.
+	AAC AudioCodec = "AAC"
+
+	// AC3 is Dolby Digital.
+	AC3 AudioCodec = "AC3"
+
+	// EAC3 is Dolby Digital Plus.
+	EAC3 AudioCodec = "EAC3"
+
+	// DTS is the core DTS codec.
+	DTS AudioCodec = "DTS"
+
+	// DTSHD is DTS-HD, including DTS-HD Master Audio.
+	DTSHD AudioCodec = "DTS-HD"
+
+	// TrueHD is Dolby TrueHD.
+	TrueHD AudioCodec = "TrueHD"
+
+	// FLAC is the Free Lossless Audio Codec.
+	FLAC AudioCodec = "FLAC"
+
+	// Opus is the Opus codec.
+	Opus AudioCodec = "Opus"
+
+	// MP3 is the MPEG-1 Audio Layer III codec.
+	MP3 AudioCodec = "MP3"
+
+	// LPCM is uncompressed audio.
+	LPCM AudioCodec = "LPCM"
+)
+
+// ReleaseInfo holds the information that is encoded in the name of a release,
+// following the usual scene and p2p naming conventions.
+// Fields that could not be found in the name are left empty.
+type ReleaseInfo struct {
+	Title string `json:"title"`
+	Year  uint   `json:"year,omitempty"`
+
+	// Seasons holds the seasons of the release, which is more than one only for multi-season packs.
+	Seasons []uint `json:"seasons,omitempty"`
+
+	// Episodes holds the episodes of the release, which is empty for season packs.
+	Episodes []uint `json:"episodes,omitempty"`
+
+	// Absolute is the absolute episode number, for anime releases that are numbered this way.
+	Absolute uint `json:"absolute,omitempty"`
+
+	Resolution    VideoQuality `json:"resolution,omitempty"`
+	Source        VideoRelease `json:"source,omitempty"`
+	VideoCodec    VideoCodec   `json:"video_codec,omitempty"`
+	HDR           []HDRFormat  `json:"hdr,omitempty"`
+	AudioCodec    AudioCodec   `json:"audio_codec,omitempty"`
+	AudioChannels string       `json:"audio_channels,omitempty"`
+	Atmos         bool         `json:"atmos,omitempty"`
+	Group         string       `json:"group,omitempty"`
+	Proper        bool         `json:"proper,omitempty"`
+	Repack        bool         `json:"repack,omitempty"`
+	Edition       string       `json:"edition,omitempty"`
+	Languages     []string     `json:"languages,omitempty"`
+}
+
+// releasePattern maps a pattern found in release names to the value it represents.
+type releasePattern struct {
+	value   string
+	pattern *regexp.Regexp
+}
+
+func releasePatterns(patterns ...string) []releasePattern {
+
+	var compiled []releasePattern
+
+	for i := 0; i < len(patterns); i += 2 {
+		compiled = append(compiled, releasePattern{patterns[i], regexp.MustCompile(`(?i)` + patterns[i+1])})
+	}
+
+	return compiled
+}
+
+var (
+	episodePattern        = regexp.MustCompile(`(?i)\bS(\d{1,3}) ?E(\d{1,4})((?: ?- ?E?\d{1,4}\b| ?E\d{1,4}\b)*)`)
+	episodeRangePattern   = regexp.MustCompile(`(?i)(-?) ?E?(\d{1,4})`)
+	crossEpisodePattern   = regexp.MustCompile(`(?i)\b(\d{1,2})x(\d{2,3})\b`)
+	seasonPattern         = regexp.MustCompile(`(?i)\b(?:S|Season ?)(\d{1,3})(?: ?- ?(?:S|Season ?)?(\d{1,3}))?\b`)
+	absolutePattern       = regexp.MustCompile(` - (\d{1,4})(?:v\d)?\b`)
+	yearPattern           = regexp.MustCompile(`\b(?:19|20)\d\d\b`)
+	audioChannelsPattern  = regexp.MustCompile(`(?i)(?:\b(?:aac|ac-?3|e-?ac-?3|ddp?|dd\+|dts(?:-?hd)?(?: ?ma)?|truehd|atmos|flac|opus|l?pcm) ?-?|\b)([1-9]) ([0-2])(?: ?ch)?\b`)
+	trailingGroupPattern  = regexp.MustCompile(`-([A-Za-z0-9]+)$`)
+	trailingTagsPattern   = regexp.MustCompile(`(?:\s*\[[^\]]*\])+$`)
+	fileExtensionPattern  = regexp.MustCompile(`(?i)\.(?:mkv|mp4|avi|m4v|ts|wmv|torrent)$`)
+	releaseSeparators     = strings.NewReplacer(".", " ", "_", " ", "(", " ", ")", " ", "[", " ", "]", " ", "{", " ", "}", " ")
+	nonGroupTrailingWords = map[string]bool{"dl": true, "rip": true, "cap": true, "hd": true, "ma": true, "x": true}
+)
+
+var resolutionPatterns = releasePatterns(
+	string(UHD), `\b(?:2160p|4k|uhd)\b`,
+	string(High), `\b1080[pi]\b`,
+	string(Medium), `\b720p\b`,
+	string(Low), `\b(?:576p|480p)\b`,
+)
+
+var videoCodecPatterns = releasePatterns(
+	string(H265), `\b(?:[xh] ?265|hevc)\b`,
+	string(H264), `\b(?:[xh] ?264|avc)\b`,
+	string(AV1), `\bav1\b`,
+	string(VP9), `\bvp9\b`,
+	string(XviD), `\b(?:xvid|divx)\b`,
+)
+
+var hdrPatterns = releasePatterns(
+	string(HDR10Plus), `\bhdr10(?:\+|plus)`,
+	string(HDR10), `\bhdr(?:10)?(?:[^+\w]|$)`,
+	string(DolbyVision), `\b(?:dv|dovi|dolby ?vision)\b`,
+	string(HLG), `\bhlg\b`,
+)
+
+// audioCodecPatterns is ordered so that the codecs with a shared prefix are checked first.
+var audioCodecPatterns = releasePatterns(
+	string(TrueHD), `\btrue ?hd\b`,
+	string(DTSHD), `\bdts-?hd\b`,
+	string(DTS), `\bdts\b`,
+	string(EAC3), `\b(?:e-?ac-?3|ddp|dd\+)`,
+	string(AC3), `\b(?:ac-?3|dd)(?:\d|\b)`,
+	string(AAC), `\baac(?:\d|\b)`,
+	string(FLAC), `\bflac\b`,
+	string(Opus), `\bopus\b`,
+	string(MP3), `\bmp3\b`,
+	string(LPCM), `\bl?pcm\b`,
+)
+
+var editionPatterns = releasePatterns(
+	"Director's Cut", `\bdirector'?s? ?cut\b`,
+	"Extended", `\bextended(?: cut| edition| version)?\b`,
+	"Theatrical", `\btheatrical(?: cut| edition)?\b`,
+	"Unrated", `\bunrated\b`,
+	"Uncut", `\buncut\b`,
+	"Final Cut", `\bfinal cut\b`,
+	"Ultimate Edition", `\bultimate edition\b`,
+	"Special Edition", `\bspecial edition\b`,
+	"Anniversary Edition", `\b(?:\d+(?:th)? )?anniversary(?: edition)?\b`,
+	"Criterion", `\bcriterion\b`,
+	"Remastered", `\bremaster(?:ed)?\b`,
+	"IMAX", `\bimax\b`,
+)
+
+var languagePatterns = releasePatterns(
+	"Multi", `\bmulti\b`,
+	"Dual Audio", `\bdual(?: ?-?audio)?\b`,
+	"English", `\b(?:eng|english)\b`,
+	"French", `\b(?:fre|french|truefrench|vff|vfq)\b`,
+	"German", `\b(?:ger|german)\b`,
+	"Italian", `\b(?:ita|italian)\b`,
+	"Spanish", `\b(?:spa|spanish|castellano|latino)\b`,
+	"Portuguese", `\b(?:por|portuguese)\b`,
+	"Russian", `\b(?:rus|russian)\b`,
+	"Hindi", `\b(?:hin|hindi)\b`,
+	"Japanese", `\b(?:jap|jpn|japanese)\b`,
+	"Korean", `\b(?:kor|korean)\b`,
+	"Chinese", `\b(?:chi|chinese)\b`,
+)
+
+var (
+	properPattern = regexp.MustCompile(`(?i)\bproper\b`)
+	repackPattern = regexp.MustCompile(`(?i)\b(?:repack|rerip)\b`)
+)
+
+// sourcePatterns holds the patterns of the labels in releaseLabels, ordered by length so that the longest label is matched first.
+var sourcePatterns = func() []releasePattern {
+
+	var patterns []releasePattern
+
+	for release, labels := range releaseLabels {
+
+		for _, label := range labels {
+
+			// Labels are matched regardless of the separators between their words.
+			words := strings.FieldsFunc(label, func(r rune) bool {
+				return r == '.' || r == ' ' || r == '-'
+			})
+
+			for i := range words {
+				words[i] = regexp.QuoteMeta(words[i])
+			}
+
+			patterns = append(patterns, releasePatterns(string(release), `\b`+strings.Join(words, `[ -]?`)+`\b`)...)
+		}
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+
+		a, b := patterns[i].pattern.String(), patterns[j].pattern.String()
+
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	return patterns
+}()
+
+// ParseReleaseName parses the name of a release, such as the title of a torrent, into its parts.
+func ParseReleaseName(name string) ReleaseInfo {
+
+	var info ReleaseInfo
+
+	name = strings.TrimSpace(name)
+	name = fileExtensionPattern.ReplaceAllString(name, "")
+
+	// Anime releases start with the group in square brackets, and usually end with tags such as the CRC.
+	if group := extractReleaseGroup(name); group != "" {
+
+		info.Group = group
+		name = strings.TrimSpace(name[strings.Index(name, "]")+1:])
+	}
+
+	// The replacements keep the length of the name, so that positions in one match the other.
+	clean := releaseSeparators.Replace(name)
+
+	// The title ends at the year, if one comes before the season or the technical details of the release,
+	// since the rest of the tags are only trusted after the title.
+	hardEnd := len(clean)
+	for _, pattern := range []*regexp.Regexp{episodePattern, crossEpisodePattern, seasonPattern, absolutePattern} {
+		hardEnd = firstMatchBefore(pattern, clean, hardEnd)
+	}
+	for _, patterns := range [][]releasePattern{resolutionPatterns, videoCodecPatterns} {
+		for _, p := range patterns {
+			hardEnd = firstMatchBefore(p.pattern, clean, hardEnd)
+		}
+	}
+
+	titleEnd := hardEnd
+
+	if years := yearPattern.FindAllStringIndex(clean[:hardEnd], -1); len(years) > 0 && years[len(years)-1][0] > 0 {
+
+		year := years[len(years)-1]
+
+		parsed, _ := strconv.ParseUint(clean[year[0]:year[1]], 10, 32)
+		info.Year = uint(parsed)
+
+		titleEnd = year[0]
+
+	} else {
+
+		for _, patterns := range [][]releasePattern{sourcePatterns, hdrPatterns, audioCodecPatterns, editionPatterns} {
+			for _, p := range patterns {
+				titleEnd = firstMatchBefore(p.pattern, clean, titleEnd)
+			}
+		}
+		for _, pattern := range []*regexp.Regexp{properPattern, repackPattern} {
+			titleEnd = firstMatchBefore(pattern, clean, titleEnd)
+		}
+	}
+
+	info.Title = strings.Join(strings.Fields(clean[:titleEnd]), " ")
+	info.Title = strings.TrimRight(info.Title, " -")
+
+	tags := clean[titleEnd:]
+
+	info.parseEpisodes(tags)
+
+	info.Resolution = VideoQuality(firstPatternValue(resolutionPatterns, tags))
+	info.Source = VideoRelease(firstPatternValue(sourcePatterns, tags))
+	info.VideoCodec = VideoCodec(firstPatternValue(videoCodecPatterns, tags))
+	info.AudioCodec = AudioCodec(firstPatternValue(audioCodecPatterns, tags))
+	info.Edition = firstPatternValue(editionPatterns, tags)
+	info.Proper = properPattern.MatchString(tags)
+	info.Repack = repackPattern.MatchString(tags)
+	info.Atmos = strings.Contains(strings.ToLower(tags), "atmos")
+
+	for _, hdr := range allPatternValues(hdrPatterns, tags) {
+		info.HDR = append(info.HDR, HDRFormat(hdr))
+	}
+
+	info.Languages = allPatternValues(languagePatterns, tags)
+
+	if m := audioChannelsPattern.FindStringSubmatch(tags); m != nil {
+		info.AudioChannels = m[1] + "." + m[2]
+	}
+
+	// Scene releases end with a dash and the group, which may be followed by tags in square brackets.
+	if m := trailingGroupPattern.FindStringSubmatchIndex(trailingTagsPattern.ReplaceAllString(name, "")); m != nil && info.Group == "" && m[0] >= titleEnd {
+
+		group := name[m[2]:m[3]]
+
+		if !nonGroupTrailingWords[strings.ToLower(group)] {
+			info.Group = group
+		}
+	}
+
+	return info
+}
+
+// parseEpisodes finds the season and episode numbers in the tags of the release.
+func (info *ReleaseInfo) parseEpisodes(tags string) {
+
+	parseUint := func(s string) uint {
+		n, _ := strconv.ParseUint(s, 10, 32)
+		return uint(n)
+	}
+
+	// appendRange appends the numbers from the last one in the slice up to n.
+	appendRange := func(numbers []uint, n uint) []uint {
+
+		last := numbers[len(numbers)-1]
+
+		for i := last + 1; i <= n && i-last <= 100; i++ {
+			numbers = append(numbers, i)
+		}
+
+		return numbers
+	}
+
+	if m := episodePattern.FindStringSubmatch(tags); m != nil {
+
+		info.Seasons = []uint{parseUint(m[1])}
+		info.Episodes = []uint{parseUint(m[2])}
+
+		for _, ep := range episodeRangePattern.FindAllStringSubmatch(m[3], -1) {
+
+			if ep[1] == "-" {
+				info.Episodes = appendRange(info.Episodes, parseUint(ep[2]))
+			} else {
+				info.Episodes = append(info.Episodes, parseUint(ep[2]))
+			}
+		}
+
+	} else if m := crossEpisodePattern.FindStringSubmatch(tags); m != nil {
+
+		info.Seasons = []uint{parseUint(m[1])}
+		info.Episodes = []uint{parseUint(m[2])}
+
+	} else if m := seasonPattern.FindStringSubmatch(tags); m != nil {
+
+		info.Seasons = []uint{parseUint(m[1])}
+
+		if m[2] != "" {
+			info.Seasons = appendRange(info.Seasons, parseUint(m[2]))
+		}
+
+	} else if m := absolutePattern.FindStringSubmatch(tags); m != nil {
+
+		info.Absolute = parseUint(m[1])
+	}
+}
+
+// MatchesEpisode returns true if the release contains the given episode.
+// An episode number of 0 in the query only matches releases of the whole season.
+func (info ReleaseInfo) MatchesEpisode(query EpisodeQuery) bool {
+
+	contains := func(numbers []uint, n uint) bool {
+		for _, number := range numbers {
+			if number == n {
+				return true
+			}
+		}
+		return false
+	}
+
+	if query.Season == 0 && query.Episode == 0 {
+		return true
+	}
+
+	if query.Season != 0 && !contains(info.Seasons, query.Season) {
+		return false
+	}
+
+	if query.Episode == 0 {
+		return len(info.Seasons) > 0 && len(info.Episodes) == 0
+	}
+
+	return contains(info.Episodes, query.Episode)
+}
+
+// HasHDR returns true if the release is in the given HDR format.
+func (info ReleaseInfo) HasHDR(format HDRFormat) bool {
+
+	for _, hdr := range info.HDR {
+		if hdr == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EpisodeString returns the season and episode numbers of the release in the S01E01 format,
+// or an empty string if it has none.
+func (info ReleaseInfo) EpisodeString() string {
+
+	if info.Absolute > 0 {
+		return fmt.Sprintf("%02d", info.Absolute)
+	}
+	if len(info.Seasons) == 0 {
+		return ""
+	}
+
+	s := fmt.Sprintf("S%02d", info.Seasons[0])
+
+	if len(info.Seasons) > 1 {
+		s += fmt.Sprintf("-S%02d", info.Seasons[len(info.Seasons)-1])
+	}
+
+	for _, episode := range info.Episodes {
+		s += fmt.Sprintf("E%02d", episode)
+	}
+
+	return s
+}
+
+// parsedRelease holds the release information of a torrent, along with the title it was parsed from.
+type parsedRelease struct {
+	title string
+	info  ReleaseInfo
+}
+
+// ReleaseInfo parses the title of the torrent into the information of the release.
+// The result is kept in the torrent and its copies until the title changes, since the filters
+// and the scoring of a search need it several times for every torrent.
+func (t *Torrent) ReleaseInfo() ReleaseInfo {
+
+	if t.release == nil || t.release.title != t.Title {
+		t.release = &parsedRelease{title: t.Title, info: ParseReleaseName(t.Title)}
+	}
+
+	return t.release.info
+}
+
+func firstMatchBefore(pattern *regexp.Regexp, s string, end int) int {
+
+	if loc := pattern.FindStringIndex(s[:end]); loc != nil {
+		return loc[0]
+	}
+
+	return end
+}
+
+func firstPatternValue(patterns []releasePattern, s string) string {
+
+	for _, p := range patterns {
+		if p.pattern.MatchString(s) {
+			return p.value
+		}
+	}
+
+	return ""
+}
+
+func allPatternValues(patterns []releasePattern, s string) []string {
+
+	var values []string
+
+	for _, p := range patterns {
+		if p.pattern.MatchString(s) {
+			values = append(values, p.value)
+		}
+	}
+
+	return values
+}
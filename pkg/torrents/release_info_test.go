@@ -0,0 +1,182 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReleaseName(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out ReleaseInfo
+	}{
+		{
+			"The.Matrix.1999.PROPER.2160p.UHD.BluRay.x265.10bit.HDR10.DV.TrueHD.Atmos.7.1-GROUP",
+			ReleaseInfo{
+				Title: "The Matrix", Year: 1999, Resolution: UHD, Source: BDRip, VideoCodec: H265,
+				HDR: []HDRFormat{HDR10, DolbyVision}, AudioCodec: TrueHD, AudioChannels: "7.1", Atmos: true,
+				Group: "GROUP", Proper: true,
+			},
+		},
+		{
+			"Blade Runner 2049 (2017) Director's Cut 1080p WEB-DL DDP5.1 H.264-NTb",
+			ReleaseInfo{
+				Title: "Blade Runner 2049", Year: 2017, Resolution: High, Source: WEBDL, VideoCodec: H264,
+				AudioCodec: EAC3, AudioChannels: "5.1", Group: "NTb", Edition: "Director's Cut",
+			},
+		},
+		{
+			"Some.Show.S03E07.REPACK.720p.HDTV.x264-KILLERS[eztv]",
+			ReleaseInfo{
+				Title: "Some Show", Seasons: []uint{3}, Episodes: []uint{7}, Resolution: Medium, Source: TVRip,
+				VideoCodec: H264, Group: "KILLERS", Repack: true,
+			},
+		},
+		{
+			"Some Show S01E01E02 1080p AMZN WEBRip DD+ 2.0 HEVC",
+			ReleaseInfo{
+				Title: "Some Show", Seasons: []uint{1}, Episodes: []uint{1, 2}, Resolution: High, Source: WEBRip,
+				VideoCodec: H265, AudioCodec: EAC3, AudioChannels: "2.0",
+			},
+		},
+		{
+			"Some.Show.2019.S02E01-E03.HDR10+.2160p",
+			ReleaseInfo{
+				Title: "Some Show", Year: 2019, Seasons: []uint{2}, Episodes: []uint{1, 2, 3}, Resolution: UHD,
+				HDR: []HDRFormat{HDR10Plus},
+			},
+		},
+		{
+			"Some Show Season 4 Complete 720p",
+			ReleaseInfo{Title: "Some Show", Seasons: []uint{4}, Resolution: Medium},
+		},
+		{
+			"Some.Show.S01-S03.1080p.BluRay.AV1.Opus.5.1",
+			ReleaseInfo{
+				Title: "Some Show", Seasons: []uint{1, 2, 3}, Resolution: High, Source: BDRip, VideoCodec: AV1,
+				AudioCodec: Opus, AudioChannels: "5.1",
+			},
+		},
+		{
+			"Some Show 2x05 480p",
+			ReleaseInfo{Title: "Some Show", Seasons: []uint{2}, Episodes: []uint{5}, Resolution: Low},
+		},
+		{
+			"[SubsPlease] One Piece - 1071 (1080p) [ABCD1234].mkv",
+			ReleaseInfo{Title: "One Piece", Absolute: 1071, Resolution: High, Group: "SubsPlease"},
+		},
+		{
+			"Charlotte's Web 2006 720p BRRip XviD AC3 MULTi FRENCH ENG",
+			ReleaseInfo{
+				Title: "Charlotte's Web", Year: 2006, Resolution: Medium, Source: BDRip, VideoCodec: XviD,
+				AudioCodec: AC3, Languages: []string{"Multi", "English", "French"},
+			},
+		},
+		{
+			"2012.2009.Extended.Cut.1080p.BluRay.DTS-HD.MA.5.1.x264",
+			ReleaseInfo{
+				Title: "2012", Year: 2009, Resolution: High, Source: BDRip, VideoCodec: H264,
+				AudioCodec: DTSHD, AudioChannels: "5.1", Edition: "Extended",
+			},
+		},
+		{
+			"Spider-Man",
+			ReleaseInfo{Title: "Spider-Man"},
+		},
+		{
+			"Some Movie WEB-DL",
+			ReleaseInfo{Title: "Some Movie", Source: WEBDL},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			info := ParseReleaseName(tt.in)
+
+			if !reflect.DeepEqual(info, tt.out) {
+				t.Errorf("got %+v, want %+v", info, tt.out)
+			}
+		})
+	}
+}
+
+func TestTorrentReleaseInfo(t *testing.T) {
+
+	torrent := Torrent{Title: "Some.Movie.2019.1080p.BluRay.x265-GROUP"}
+
+	if info := torrent.ReleaseInfo(); info.Group != "GROUP" || torrent.release == nil {
+		t.Fatalf("got %+v, want the release information to be kept", info)
+	}
+
+	// Copies share the parsed information, until their title changes.
+	cached := torrent.release
+	other := torrent
+
+	if other.ReleaseInfo(); other.release != cached {
+		t.Errorf("expected the copy to reuse the parsed release information")
+	}
+
+	other.Title = "Some.Movie.2019.720p.WEB-DL.x264-OTHER"
+
+	if info := other.ReleaseInfo(); info.Group != "OTHER" || info.Resolution != Medium {
+		t.Errorf("got %+v, want the changed title to be parsed", info)
+	}
+
+	if info := torrent.ReleaseInfo(); info.Group != "GROUP" {
+		t.Errorf("got %+v, want the original torrent to be unaffected", info)
+	}
+}
+
+func TestMatchesEpisode(t *testing.T) {
+
+	table := []struct {
+		title string
+		query EpisodeQuery
+		out   bool
+	}{
+		{"Some.Show.S03E07.720p", EpisodeQuery{3, 7}, true},
+		{"Some.Show.S03E07.720p", EpisodeQuery{3, 8}, false},
+		{"Some.Show.S03E07.720p", EpisodeQuery{2, 7}, false},
+		{"Some.Show.S03E07.720p", EpisodeQuery{3, 0}, false},
+		{"Some.Show.S03E07E08.720p", EpisodeQuery{3, 8}, true},
+		{"Some.Show.S03.720p", EpisodeQuery{3, 0}, true},
+		{"Some.Show.S03.720p", EpisodeQuery{3, 7}, false},
+		{"Some.Show.S01-S03.720p", EpisodeQuery{2, 0}, true},
+		{"Some.Show.720p", EpisodeQuery{3, 7}, false},
+		{"Some.Show.720p", EpisodeQuery{0, 0}, true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			if m := ParseReleaseName(tt.title).MatchesEpisode(tt.query); m != tt.out {
+				t.Errorf("got %v, want %v for %v", m, tt.out, tt.query)
+			}
+		})
+	}
+}
+
+func TestEpisodeString(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out string
+	}{
+		{"Some.Show.S03E07.720p", "S03E07"},
+		{"Some.Show.S03E07E08.720p", "S03E07E08"},
+		{"Some.Show.S01-S03.720p", "S01-S03"},
+		{"[Group] Some Show - 07 [720p]", "07"},
+		{"Some.Movie.2019.720p", ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			if s := ParseReleaseName(tt.in).EpisodeString(); s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
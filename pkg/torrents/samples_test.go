@@ -0,0 +1,42 @@
+package torrents
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// sampleRoute serves one of the documents in test_samples at a path of a test server.
+type sampleRoute struct {
+	path   string
+	sample string
+
+	// match returns false for the requests that should receive the empty response instead of the sample,
+	// and is not checked if nil.
+	match func(query url.Values) bool
+	empty string
+}
+
+// sampleServer starts a test server that responds with the sample documents of the given routes,
+// standing in for the API or the feed of a torrent source.
+func sampleServer(routes ...sampleRoute) *httptest.Server {
+
+	mux := http.NewServeMux()
+
+	for _, route := range routes {
+
+		route := route
+
+		mux.HandleFunc(route.path, func(w http.ResponseWriter, r *http.Request) {
+
+			if route.match != nil && !route.match(r.URL.Query()) {
+				w.Write([]byte(route.empty))
+				return
+			}
+
+			http.ServeFile(w, r, "../../test_samples/"+route.sample)
+		})
+	}
+
+	return httptest.NewServer(mux)
+}
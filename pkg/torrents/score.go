@@ -0,0 +1,202 @@
+package torrents
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScoreWeights holds the weight of each factor in the score of a torrent.
+// Each factor is valued between 0 and 1, so a torrent's score is at most the sum of the weights.
+type ScoreWeights struct {
+	Quality     float64 `toml:"quality"`
+	ReleaseType float64 `toml:"release_type"`
+	Codec       float64 `toml:"codec"`
+	Size        float64 `toml:"size"`
+	Seeders     float64 `toml:"seeders"`
+	Trusted     float64 `toml:"trusted"`
+	Group       float64 `toml:"group"`
+	Age         float64 `toml:"age"`
+}
+
+// ScoringConfig holds the configuration of the scoring model, which picks the best among the torrents that pass the filters.
+type ScoringConfig struct {
+	Weights ScoreWeights `toml:"weights"`
+
+	// PreferredCodecs holds the video codecs to prefer, with the first one being the most preferred.
+	PreferredCodecs []string `toml:"preferred_codecs"`
+
+	// PreferredGroups holds the release groups to prefer.
+	PreferredGroups []string `toml:"preferred_groups"`
+
+	// SizePerMinute holds the ideal size of each video quality, in megabytes per minute of runtime.
+	SizePerMinute map[string]float64 `toml:"mb_per_minute"`
+}
+
+// DefaultScoreWeights holds the weights that are used when none are configured.
+// The quality outweighs all other factors combined, so that the best available quality is always picked.
+var DefaultScoreWeights = ScoreWeights{
+	Quality:     100,
+	ReleaseType: 10,
+	Codec:       2,
+	Size:        2,
+	Seeders:     6,
+	Trusted:     2,
+	Group:       2,
+	Age:         1,
+}
+
+// DefaultSizePerMinute holds the ideal sizes of each video quality that are used when none are configured.
+var DefaultSizePerMinute = map[string]float64{
+	string(Default): 5,
+	string(Low):     8,
+	string(Medium):  15,
+	string(High):    30,
+	string(UHD):     80,
+}
+
+// ScoreFactor is one of the factors that make up the score of a torrent.
+type ScoreFactor struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail"`
+}
+
+// Score returns the contribution of the factor to the score of the torrent.
+func (f ScoreFactor) Score() float64 {
+	return f.Value * f.Weight
+}
+
+// String returns a short description of the factor and how it was scored.
+func (f ScoreFactor) String() string {
+	return fmt.Sprintf("%v: %.2f x %v = %.2f (%v)", f.Name, f.Value, f.Weight, f.Score(), f.Detail)
+}
+
+// ScoredTorrent holds a torrent along with its score and the factors it was calculated from.
+type ScoredTorrent struct {
+	Torrent Torrent       `json:"torrent"`
+	Score   float64       `json:"score"`
+	Factors []ScoreFactor `json:"factors"`
+}
+
+// ScoreTorrent calculates the score of the torrent according to the scoring configuration of the filters.
+func (f SearchFilters) ScoreTorrent(torrent Torrent) ScoredTorrent {
+
+	weights := f.Scoring.Weights
+	if weights == (ScoreWeights{}) {
+		weights = DefaultScoreWeights
+	}
+
+	sizePerMinute := f.Scoring.SizePerMinute
+	if sizePerMinute == nil {
+		sizePerMinute = DefaultSizePerMinute
+	}
+
+	info := torrent.ReleaseInfo()
+	release := torrent.GetVideoRelease()
+
+	scored := ScoredTorrent{Torrent: torrent}
+
+	addFactor := func(name string, value float64, weight float64, detail string) {
+
+		factor := ScoreFactor{Name: name, Value: value, Weight: weight, Detail: detail}
+
+		scored.Score += factor.Score()
+		scored.Factors = append(scored.Factors, factor)
+	}
+
+	addFactor("quality", float64(torrent.VideoQuality.numeric())/float64(UHD.numeric()),
+		weights.Quality, string(torrent.VideoQuality))
+
+	releaseDetail := string(release)
+	if release == "" {
+		releaseDetail = "unknown"
+	}
+	addFactor("release type", float64(release.numeric())/float64(len(releaseRanking)), weights.ReleaseType, releaseDetail)
+
+	codecValue, codecDetail := 0.0, "not preferred"
+	if info.VideoCodec == "" {
+		codecDetail = "unknown"
+	}
+	for i, codec := range f.Scoring.PreferredCodecs {
+		if info.matchesCodec(codec) {
+			codecValue = float64(len(f.Scoring.PreferredCodecs)-i) / float64(len(f.Scoring.PreferredCodecs))
+			codecDetail = fmt.Sprintf("%v, preference %v of %v", info.VideoCodec, i+1, len(f.Scoring.PreferredCodecs))
+			break
+		}
+	}
+	addFactor("codec", codecValue, weights.Codec, codecDetail)
+
+	sizeValue, sizeDetail := 0.0, "unknown runtime"
+	if target := sizePerMinute[string(torrent.VideoQuality)]; f.Runtime > 0 && target > 0 && torrent.Size > 0 {
+
+		actual := float64(torrent.Size) / 1000 / f.Runtime.Minutes()
+
+		// Half or double the ideal size scores 0.5, while a quarter or four times the ideal size scores 0.
+		sizeValue = math.Max(0, 1-math.Abs(math.Log2(actual/target))/2)
+		sizeDetail = fmt.Sprintf("%.1f MB/min, ideal %.1f MB/min", actual, target)
+	}
+	addFactor("size", sizeValue, weights.Size, sizeDetail)
+
+	// Seeders are scored logarithmically, with 1000 or more seeders scoring 1.
+	seedersValue, seedersDetail := math.Min(1, math.Log10(float64(torrent.Seeders)+1)/3), fmt.Sprint(torrent.Seeders)
+	if torrent.UnknownPeers {
+		seedersValue, seedersDetail = 0, "unknown"
+	}
+	addFactor("seeders", seedersValue, weights.Seeders, seedersDetail)
+
+	trustedValue := 0.0
+	if torrent.VerifiedUploader {
+		trustedValue = 1
+	}
+	addFactor("trusted", trustedValue, weights.Trusted, fmt.Sprint(torrent.VerifiedUploader))
+
+	groupValue, groupDetail := 0.0, info.Group
+	if groupDetail == "" {
+		groupDetail = "unknown"
+	}
+	for _, group := range f.Scoring.PreferredGroups {
+		if (info.Group != "" && strings.EqualFold(group, info.Group)) || (torrent.Uploader != "" && strings.EqualFold(group, torrent.Uploader)) {
+			groupValue = 1
+			groupDetail = group + ", preferred"
+			break
+		}
+	}
+	addFactor("group", groupValue, weights.Group, groupDetail)
+
+	// Newer uploads are preferred, with the value halving for every month since the upload.
+	ageValue, ageDetail := 0.0, "unknown"
+	if !torrent.UploadTime.IsZero() {
+
+		age := time.Since(torrent.UploadTime)
+		if age < 0 {
+			age = 0
+		}
+
+		ageValue = math.Pow(0.5, age.Hours()/24/30)
+		ageDetail = fmt.Sprintf("%.0f days", age.Hours()/24)
+	}
+	addFactor("age", ageValue, weights.Age, ageDetail)
+
+	return scored
+}
+
+// ScoreTorrents calculates the scores of the given torrents and returns them sorted by their score in descending order.
+// Among torrents with equal scores, the ones earlier in the list come first.
+func (f SearchFilters) ScoreTorrents(torrents []Torrent) []ScoredTorrent {
+
+	var scored []ScoredTorrent
+
+	for _, torrent := range torrents {
+		scored = append(scored, f.ScoreTorrent(torrent))
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	return scored
+}
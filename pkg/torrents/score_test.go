@@ -0,0 +1,128 @@
+package torrents
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreTorrent(t *testing.T) {
+
+	filters := SearchFilters{
+		Scoring: ScoringConfig{
+			PreferredCodecs: []string{"x265", "x264"},
+			PreferredGroups: []string{"GOODGROUP"},
+		},
+		Runtime: 100 * time.Minute,
+	}
+
+	table := []struct {
+		torrent Torrent
+		factor  string
+		value   float64
+	}{
+		{Torrent{VideoQuality: UHD}, "quality", 1},
+		{Torrent{VideoQuality: High}, "quality", 0.75},
+		{Torrent{VideoQuality: Default}, "quality", 0},
+		{Torrent{Title: "Some.Movie.2019.1080p.BluRay"}, "release type", 1},
+		{Torrent{Title: "Some.Movie.2019.1080p"}, "release type", 0},
+		{Torrent{Title: "Some.Movie.2019.1080p.HEVC"}, "codec", 1},
+		{Torrent{Title: "Some.Movie.2019.1080p.x264"}, "codec", 0.5},
+		{Torrent{Title: "Some.Movie.2019.1080p.AV1"}, "codec", 0},
+		{Torrent{VideoQuality: High, Size: 3000000}, "size", 1},
+		{Torrent{VideoQuality: High, Size: 6000000}, "size", 0.5},
+		{Torrent{VideoQuality: High, Size: 750000}, "size", 0},
+		{Torrent{VideoQuality: High}, "size", 0},
+		{Torrent{Seeders: 999}, "seeders", 1},
+		{Torrent{Seeders: 5000}, "seeders", 1},
+		{Torrent{Seeders: 0}, "seeders", 0},
+		{Torrent{UnknownPeers: true}, "seeders", 0},
+		{Torrent{VerifiedUploader: true}, "trusted", 1},
+		{Torrent{Title: "Some.Movie.2019.1080p.x264-GoodGroup"}, "group", 1},
+		{Torrent{Title: "Some.Movie.2019.1080p.x264-OTHER"}, "group", 0},
+		{Torrent{Uploader: "goodgroup"}, "group", 1},
+		{Torrent{UploadTime: time.Now()}, "age", 1},
+		{Torrent{}, "age", 0},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.factor+" "+tt.torrent.Title, func(t *testing.T) {
+
+			scored := filters.ScoreTorrent(tt.torrent)
+
+			found := false
+			var total float64
+
+			for _, factor := range scored.Factors {
+
+				total += factor.Score()
+
+				if factor.Name == tt.factor {
+
+					found = true
+
+					if diff := factor.Value - tt.value; diff > 0.01 || diff < -0.01 {
+						t.Errorf("got %v, want %v", factor, tt.value)
+					}
+				}
+			}
+
+			if !found {
+				t.Errorf("factor %v not found in %v", tt.factor, scored.Factors)
+			}
+			if total != scored.Score {
+				t.Errorf("got score %v, want the sum of the factors %v", scored.Score, total)
+			}
+		})
+	}
+}
+
+func TestScoreTorrents(t *testing.T) {
+
+	torrents := []Torrent{
+		{Title: "Some.Movie.2019.720p.BluRay.x265", VideoQuality: Medium, Seeders: 1000, VerifiedUploader: true},
+		{Title: "Some.Movie.2019.1080p.WEBRip", VideoQuality: High, Seeders: 10},
+		{Title: "Some.Movie.2019.1080p.BluRay", VideoQuality: High, Seeders: 10},
+		{Title: "Some.Movie.2019.1080p.BluRay.x264", VideoQuality: High, Seeders: 10},
+	}
+
+	table := []struct {
+		scoring ScoringConfig
+		out     []string
+	}{
+		{
+			ScoringConfig{},
+			[]string{
+				"Some.Movie.2019.1080p.BluRay", "Some.Movie.2019.1080p.BluRay.x264",
+				"Some.Movie.2019.1080p.WEBRip", "Some.Movie.2019.720p.BluRay.x265",
+			},
+		},
+		{
+			ScoringConfig{PreferredCodecs: []string{"x264"}},
+			[]string{
+				"Some.Movie.2019.1080p.BluRay.x264", "Some.Movie.2019.1080p.BluRay",
+				"Some.Movie.2019.1080p.WEBRip", "Some.Movie.2019.720p.BluRay.x265",
+			},
+		},
+		{
+			ScoringConfig{Weights: ScoreWeights{Seeders: 1}},
+			[]string{
+				"Some.Movie.2019.720p.BluRay.x265", "Some.Movie.2019.1080p.WEBRip",
+				"Some.Movie.2019.1080p.BluRay", "Some.Movie.2019.1080p.BluRay.x264",
+			},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.out[0], func(t *testing.T) {
+
+			scored := SearchFilters{Scoring: tt.scoring}.ScoreTorrents(torrents)
+
+			for i, title := range tt.out {
+
+				if scored[i].Torrent.Title != title {
+					t.Errorf("got %v at %v, want %v", scored[i].Torrent.Title, i, title)
+				}
+			}
+		})
+	}
+}
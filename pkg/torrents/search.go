@@ -1,7 +1,10 @@
 package torrents
 
 import (
+	"context"
+	"regexp"
 	"strings"
+	"time"
 
 	"gitlab.com/haath/gobytes"
 	"gitlab.com/haath/goirate/pkg/utils"
@@ -13,21 +16,111 @@ type UploaderFilters struct {
 	Blacklist []string `long:"blacklist" description:"Add to a blacklist of uploaders, to avoid torrents from them." toml:"blacklist"`
 }
 
+// ReleaseTypeFilters holds filters regarding the acceptance of a torrent's release type.
+type ReleaseTypeFilters struct {
+	Whitelist []VideoRelease `long:"release" description:"Add to a whitelist of release types, to only consider torrents of them." toml:"whitelist"`
+	Blacklist []VideoRelease `long:"exclude-release" description:"Add to a blacklist of release types, to avoid torrents of them." toml:"blacklist"`
+}
+
 // SearchFilters holds conditions and filters, used to search for specific torrents.
 type SearchFilters struct {
-	VerifiedUploader bool            `long:"trusted" description:"Only consider torrents where the uploader is either VIP or Trusted." toml:"trusted"`
-	MinQuality       VideoQuality    `long:"min-quality" description:"Minimum acceptable torrent quality (inclusive)." toml:"min-quality"`
-	MaxQuality       VideoQuality    `long:"max-quality" description:"Maximum acceptable torrent quality (inclusive)." toml:"max-quality"`
-	MinSize          string          `long:"min-size" description:"Minimum acceptable torrent size." toml:"min-size"`
-	MaxSize          string          `long:"max-size" description:"Maximum acceptable torrent size." toml:"max-size"`
-	MinSeeders       int             `long:"min-seeders" description:"Minimum acceptable amount of seeders." toml:"min-seeders"`
-	Uploaders        UploaderFilters `toml:"uploaders"`
+	VerifiedUploader bool               `long:"trusted" description:"Only consider torrents where the uploader is either VIP or Trusted." toml:"trusted"`
+	MinQuality       VideoQuality       `long:"min-quality" description:"Minimum acceptable torrent quality (inclusive)." toml:"min-quality"`
+	MaxQuality       VideoQuality       `long:"max-quality" description:"Maximum acceptable torrent quality (inclusive)." toml:"max-quality"`
+	MinSize          string             `long:"min-size" description:"Minimum acceptable torrent size." toml:"min-size"`
+	MaxSize          string             `long:"max-size" description:"Maximum acceptable torrent size." toml:"max-size"`
+	MinSizePerMinute float64            `long:"min-mb-per-min" description:"Minimum acceptable torrent size in megabytes per minute of runtime, when the runtime is known." toml:"min-mb-per-min"`
+	MaxSizePerMinute float64            `long:"max-mb-per-min" description:"Maximum acceptable torrent size in megabytes per minute of runtime, when the runtime is known." toml:"max-mb-per-min"`
+	MinSeeders       int                `long:"min-seeders" description:"Minimum acceptable amount of seeders." toml:"min-seeders"`
+	MinRelease       VideoRelease       `long:"min-release" description:"Minimum acceptable release type, such as WEB-DL or Blu-ray (inclusive)." toml:"min-release"`
+	MinAge           string             `long:"min-age" description:"Minimum acceptable time since the torrent was uploaded, such as 2h, 7d, 2w or 1y." toml:"min-age"`
+	MaxAge           string             `long:"max-age" description:"Maximum acceptable time since the torrent was uploaded, such as 2h, 7d, 2w or 1y." toml:"max-age"`
+	Uploaders        UploaderFilters    `toml:"uploaders"`
+	ReleaseTypes     ReleaseTypeFilters `toml:"releases"`
+	Release          ReleaseFilters     `toml:"release"`
+	Filter           string             `long:"filter" description:"Only consider torrents matching a filter expression, such as '1080p and (x265 or size < 2GB) and not uploader:foo'." toml:"filter"`
 
 	// Internal, used to pass multiple substrings for filtering.
-	SearchTerms   []string
-	MirrorURL     string
-	ProxyListURL  string
-	MirrorFilters MirrorFilters
+	SearchTerms     []string
+	IMDbID          string
+	Episode         *EpisodeQuery
+	MirrorURL       string
+	ProxyListURL    string
+	MirrorFilters   MirrorFilters
+	MirrorStatsPath string        `toml:"-"`
+	ProxyListCache  string        `toml:"-"`
+	Title           TitleFilters  `toml:"-"`
+	Sources         SourceConfig  `toml:"-"`
+	Scoring         ScoringConfig `toml:"-"`
+
+	// TextSearchOnly skips the sources that look up the IMDbID, such as when searching for an alias of a title
+	// whose IMDb ID has already been looked up.
+	TextSearchOnly bool `toml:"-"`
+
+	// Runtime is the duration of the video that is searched for, if it is known.
+	Runtime time.Duration `toml:"-"`
+
+	// Context cancels the requests made to the sources, when it is cancelled or expires.
+	Context context.Context `toml:"-"`
+
+	// expression is the parsed Filter, set by CompileFilter.
+	expression *FilterExpression
+
+	// searchTermPatterns holds the patterns of the normalized search terms, which are nil for the terms that do not end
+	// with a number, set by CompileFilter.
+	searchTermPatterns map[string]*regexp.Regexp
+}
+
+// CompileFilter parses the filter expression, the title patterns and the search terms once, so that they are not parsed
+// again for every torrent that is checked, and returns an error if any of them is invalid.
+func (f *SearchFilters) CompileFilter() error {
+
+	if err := f.Title.Validate(); err != nil {
+		return err
+	}
+
+	f.searchTermPatterns = make(map[string]*regexp.Regexp)
+
+	for _, searchTerm := range f.SearchTerms {
+
+		searchTerm = utils.NormalizeQuery(searchTerm)
+		f.searchTermPatterns[searchTerm] = searchTermPattern(searchTerm)
+	}
+
+	expr, err := f.filterExpression()
+
+	if err != nil {
+		return err
+	}
+
+	f.expression = expr
+
+	return nil
+}
+
+// filterExpression returns the parsed filter expression, or nil if there is none.
+// The expression is only parsed if it has not been compiled already.
+func (f SearchFilters) filterExpression() (*FilterExpression, error) {
+
+	if f.Filter == "" {
+		return nil, nil
+	}
+
+	if f.expression != nil && f.expression.source == f.Filter {
+		return f.expression, nil
+	}
+
+	return ParseFilterExpression(f.Filter)
+}
+
+// searchContext returns the context of the requests made to the sources.
+func (f SearchFilters) searchContext() context.Context {
+
+	if f.Context == nil {
+		return context.Background()
+	}
+
+	return f.Context
 }
 
 // MinSizeKB returns the specified minimum size in kilobytes.
@@ -48,6 +141,68 @@ func (f SearchFilters) MaxSizeKB() (int64, error) {
 	return int64(v.KBytes()), err
 }
 
+// MinAgeDuration returns the specified minimum age of a torrent, or zero if none is specified.
+func (f SearchFilters) MinAgeDuration() (time.Duration, error) {
+
+	if f.MinAge == "" {
+		return 0, nil
+	}
+
+	return parseAge(f.MinAge)
+}
+
+// MaxAgeDuration returns the specified maximum age of a torrent, or zero if none is specified.
+func (f SearchFilters) MaxAgeDuration() (time.Duration, error) {
+
+	if f.MaxAge == "" {
+		return 0, nil
+	}
+
+	return parseAge(f.MaxAge)
+}
+
+// AgeOk will return true if the given upload time is within the minimum and maximum age of the filters.
+// Torrents whose upload time is unknown are always accepted.
+func (f SearchFilters) AgeOk(uploadTime time.Time) bool {
+
+	if uploadTime.IsZero() {
+		return true
+	}
+
+	age := time.Since(uploadTime)
+
+	minAge, _ := f.MinAgeDuration()
+	maxAge, _ := f.MaxAgeDuration()
+
+	return (minAge == 0 || age >= minAge) && (maxAge == 0 || age <= maxAge)
+}
+
+// SizePerMinuteOk will return true if the size of the torrent per minute of runtime is within the limits of the filters.
+// Torrents are always accepted when the runtime or their size is unknown, as well as season packs
+// whose number of episodes is unknown. Torrents of multiple episodes are compared against the runtime of all of them.
+func (f SearchFilters) SizePerMinuteOk(torrent *Torrent) bool {
+
+	if f.Runtime <= 0 || torrent.Size <= 0 || (f.MinSizePerMinute <= 0 && f.MaxSizePerMinute <= 0) {
+		return true
+	}
+
+	info := torrent.ReleaseInfo()
+
+	if len(info.Seasons) > 0 && len(info.Episodes) == 0 {
+		return true
+	}
+
+	runtime := f.Runtime.Minutes()
+	if len(info.Episodes) > 1 {
+		runtime *= float64(len(info.Episodes))
+	}
+
+	sizePerMinute := float64(torrent.Size) / 1000 / runtime
+
+	return (f.MinSizePerMinute <= 0 || sizePerMinute >= f.MinSizePerMinute) &&
+		(f.MaxSizePerMinute <= 0 || sizePerMinute <= f.MaxSizePerMinute)
+}
+
 // UploaderOk will return true if the given uploader's name is acceptable according to the blacklist and whitelist
 // of the filters.
 func (f SearchFilters) UploaderOk(uploader string) bool {
@@ -65,6 +220,29 @@ func (f SearchFilters) UploaderOk(uploader string) bool {
 		(len(f.Uploaders.Whitelist) == 0 || contains(f.Uploaders.Whitelist, uploader))
 }
 
+// ReleaseOk will return true if the given release type is acceptable according to the minimum release type,
+// and the blacklist and whitelist of the filters. Unknown release types are only rejected by the whitelist.
+func (f SearchFilters) ReleaseOk(release VideoRelease) bool {
+
+	contains := func(s []VideoRelease, e VideoRelease) bool {
+		for _, a := range s {
+			if e != "" && ParseVideoRelease(string(a)) == e {
+				return true
+			}
+		}
+		return false
+	}
+
+	release = ParseVideoRelease(string(release))
+
+	if release != "" && f.MinRelease != "" && release.WorseThan(f.MinRelease) {
+		return false
+	}
+
+	return (len(f.ReleaseTypes.Blacklist) == 0 || !contains(f.ReleaseTypes.Blacklist, release)) &&
+		(len(f.ReleaseTypes.Whitelist) == 0 || contains(f.ReleaseTypes.Whitelist, release))
+}
+
 // IsOk returns true if the given torrent complies with the filters.
 func (f SearchFilters) IsOk(torrent *Torrent) bool {
 
@@ -84,6 +262,9 @@ func (f SearchFilters) IsOk(torrent *Torrent) bool {
 		(torrent.Size < minSize && minSize > 0) {
 		return false
 	}
+	if !f.SizePerMinuteOk(torrent) {
+		return false
+	}
 
 	// Check the quality.
 	if (f.MinQuality != "" && torrent.VideoQuality.WorseThan(f.MinQuality)) ||
@@ -91,18 +272,64 @@ func (f SearchFilters) IsOk(torrent *Torrent) bool {
 		return false
 	}
 
-	// Check the number of seeders.
-	if torrent.Seeders < f.MinSeeders {
+	// Check the release type.
+	if !f.ReleaseOk(torrent.GetVideoRelease()) {
+		return false
+	}
+
+	// Check the codecs and formats of the release.
+	if !f.Release.IsOk(torrent.ReleaseInfo()) {
+		return false
+	}
+
+	// Check the number of seeders, unless the source does not report it.
+	if !torrent.UnknownPeers && torrent.Seeders < f.MinSeeders {
+		return false
+	}
+
+	// Check the time since the upload.
+	if !f.AgeOk(torrent.UploadTime) {
+		return false
+	}
+
+	// Check the filter expression, which rejects every torrent if it is invalid.
+	if expr, err := f.filterExpression(); err != nil || (expr != nil && !expr.Matches(torrent)) {
+		return false
+	}
+
+	// Check the IMDb ID, for torrents whose source provides one.
+	imdbMatch := false
+	if f.IMDbID != "" && torrent.IMDbID != "" {
+
+		if !strings.EqualFold(f.IMDbID, torrent.IMDbID) {
+			return false
+		}
+
+		imdbMatch = true
+	}
+
+	// For series, a matching torrent still needs to be for the right episode.
+	if imdbMatch && f.Episode != nil && !torrent.ReleaseInfo().MatchesEpisode(*f.Episode) {
+		return false
+	}
+
+	// Check for search terms in the title, unless the torrent is already known to be a match.
+	searchTerms := f.SearchTerms
+	if imdbMatch {
+		searchTerms = nil
+	}
+
+	// Check the required and ignored words and patterns, even for torrents that matched by their IMDb ID.
+	if !f.Title.IsOk(torrent.Title) {
 		return false
 	}
 
-	// Check for search terms in the title.
 	torrentTitle := utils.NormalizeQuery(torrent.Title)
-	for _, searchTerm := range f.SearchTerms {
+	for _, searchTerm := range searchTerms {
 
 		searchTerm = utils.NormalizeQuery(searchTerm)
 
-		if !strings.Contains(torrentTitle, searchTerm) {
+		if !f.containsSearchTerm(torrentTitle, searchTerm) {
 			// Search term not found.
 			return false
 		}
@@ -111,17 +338,64 @@ func (f SearchFilters) IsOk(torrent *Torrent) bool {
 	return true
 }
 
+var (
+	trailingNumberPattern  = regexp.MustCompile(`^(.*?)0*(\d+)$`)
+	numberContinuesPattern = regexp.MustCompile(`^(?:\d| \d{1,3}(?: |$))`)
+)
+
+// searchTermPattern returns the pattern of a normalized search term that ends with a number, or nil if it does not.
+func searchTermPattern(term string) *regexp.Regexp {
+
+	m := trailingNumberPattern.FindStringSubmatch(term)
+
+	if m == nil {
+		return nil
+	}
+
+	return regexp.MustCompile(regexp.QuoteMeta(m[1]) + `0*` + m[2])
+}
+
+// containsSearchTerm returns true if the normalized title contains the normalized search term.
+// A number at the end of the term matches the same number with any leading zeros, but not the start of a longer number
+// or of a batch such as 01 12, so that episode 107 does not match 1071, and episode 01 does not match 010 or 01-12.
+// The pattern of the term is only compiled if it has not been compiled by CompileFilter already.
+func (f SearchFilters) containsSearchTerm(title string, term string) bool {
+
+	pattern, ok := f.searchTermPatterns[term]
+
+	if !ok {
+		pattern = searchTermPattern(term)
+	}
+
+	if pattern == nil {
+		return strings.Contains(title, term)
+	}
+
+	for _, match := range pattern.FindAllStringIndex(title, -1) {
+
+		if !numberContinuesPattern.MatchString(title[match[1]:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // FilterTorrents filters the given list of torrents, returning only the ones that
 // comply with the filters.
 func (f SearchFilters) FilterTorrents(torrents []Torrent) []Torrent {
 
 	var filtered []Torrent
 
-	for _, torrent := range torrents {
+	// An invalid filter expression rejects every torrent, and is reported by SearchTorrents.
+	f.CompileFilter()
+
+	// The torrents are checked in place, so that the release information parsed from their titles is kept.
+	for i := range torrents {
 
-		if f.IsOk(&torrent) {
+		if f.IsOk(&torrents[i]) {
 
-			filtered = append(filtered, torrent)
+			filtered = append(filtered, torrents[i])
 		}
 	}
 
@@ -147,36 +421,64 @@ func (f SearchFilters) FilterTorrentsCount(torrents []Torrent, count uint) []Tor
 }
 
 // SearchTorrents is a shortcut function, to search for torrents given the filters,
-// so that either the specified `MirrorURL` is used or all of them are searched.
+// so that all of the enabled sources are searched and their results merged.
+// If an `IMDbID` is specified, sources that support it will look up the movie or the `Episode`
+// of the series by its ID instead.
 func (f SearchFilters) SearchTorrents(query string) ([]Torrent, error) {
 
-	var trnts []Torrent
-	var err error
+	if err := f.CompileFilter(); err != nil {
+		return nil, err
+	}
+
+	if _, err := f.MinAgeDuration(); err != nil {
+		return nil, err
+	}
+
+	if _, err := f.MaxAgeDuration(); err != nil {
+		return nil, err
+	}
+
+	sources, err := f.GetSources()
+
+	if err != nil {
+		return nil, err
+	}
 
-	if f.MirrorURL != "" {
+	if f.TextSearchOnly && f.IMDbID != "" {
 
-		// A specific mirror was specified.
-		scraper := NewScraper(f.MirrorURL)
-		trnts, err = scraper.Search(query)
+		var textSources []Source
 
-	} else {
+		for _, source := range sources {
 
-		// A specific mirror wasn't specified.
-		mirrorScraper := MirrorScraper{
-			proxySourceURL: f.ProxyListURL,
-			mirrorFilters:  f.MirrorFilters,
+			_, episodeSource := source.(EpisodeSource)
+			_, movieSource := source.(MovieSource)
+
+			if !(episodeSource && f.Episode != nil) && !(movieSource && f.Episode == nil) {
+				textSources = append(textSources, source)
+			}
 		}
 
-		trnts, err = mirrorScraper.GetTorrents(query)
+		return SearchSources(textSources, query)
 	}
 
-	return trnts, err
+	if f.IMDbID != "" && f.Episode != nil {
+
+		return SearchEpisodeSources(sources, f.IMDbID, *f.Episode, query)
+
+	} else if f.IMDbID != "" {
+
+		return SearchMovieSources(sources, f.IMDbID, query)
+	}
+
+	return SearchSources(sources, query)
 }
 
 // SearchVideoTorrents is a shortcut function, to search for video torrents given the filters,
-// so that either the specified `MirrorURL` is used or all of them are searched.
+// so that all of the enabled sources are searched.
 func (f SearchFilters) SearchVideoTorrents(query string) ([]Torrent, error) {
 
+	f = f.withDefaultExcludedReleases()
+
 	trnts, err := f.SearchTorrents(query)
 
 	var perQualitySlice []Torrent
@@ -191,10 +493,34 @@ func (f SearchFilters) SearchVideoTorrents(query string) ([]Torrent, error) {
 	return perQualitySlice, err
 }
 
-// PickVideoTorrent functions similar to SearchTorrentList(), but instead returns the torrent with the best available video quality
-// with at least one seeder.
+// withDefaultExcludedReleases returns the filters with the DefaultExcludedReleases blacklisted, unless a blacklist
+// has been set, even an empty one. They only apply to video searches, so that plain searches still list every torrent.
+func (f SearchFilters) withDefaultExcludedReleases() SearchFilters {
+
+	if f.ReleaseTypes.Blacklist == nil {
+		f.ReleaseTypes.Blacklist = DefaultExcludedReleases
+	}
+
+	return f
+}
+
+// PickVideoTorrent functions similar to SearchTorrentList(), but instead returns the torrent with the best score
+// among the best torrents of each video quality, with at least one seeder.
 func PickVideoTorrent(torrents []Torrent, filters SearchFilters) (*Torrent, error) {
 
+	scored, err := RankVideoTorrents(torrents, filters)
+
+	if err != nil || len(scored) == 0 {
+		return nil, err
+	}
+
+	return &scored[0].Torrent, nil
+}
+
+// RankVideoTorrents returns the candidates considered by PickVideoTorrent, which are the best torrents of each
+// video quality with at least one seeder, sorted by their score in descending order.
+func RankVideoTorrents(torrents []Torrent, filters SearchFilters) ([]ScoredTorrent, error) {
+
 	trnts, err := SearchVideoTorrentList(torrents, filters)
 
 	if err != nil {
@@ -202,42 +528,35 @@ func PickVideoTorrent(torrents []Torrent, filters SearchFilters) (*Torrent, erro
 	}
 
 	ok := func(t *Torrent) bool {
-		return (t.Seeders > 0) &&
+		return t.MaybeSeeded() &&
+			filters.ReleaseOk(t.GetVideoRelease()) &&
 			(filters.MaxQuality == "" || !t.VideoQuality.BetterThan(filters.MaxQuality)) &&
 			(filters.MinQuality == "" || !t.VideoQuality.WorseThan(filters.MinQuality))
 	}
 
-	var torrent *Torrent
-
-	if t, exists := trnts[UHD]; exists && ok(t) {
-
-		torrent = t
+	var candidates []Torrent
 
-	} else if t, exists := trnts[High]; exists && ok(t) {
+	for _, quality := range []VideoQuality{UHD, High, Medium, Low, Default} {
 
-		torrent = t
-
-	} else if t, exists := trnts[Medium]; exists && ok(t) {
-
-		torrent = t
-
-	} else if t, exists := trnts[Low]; exists && ok(t) {
-
-		torrent = t
-
-	} else if t, exists := trnts[Default]; exists && ok(t) {
-
-		torrent = t
+		if t, exists := trnts[quality]; exists && ok(t) {
+			candidates = append(candidates, *t)
+		}
 	}
 
-	return torrent, nil
+	return filters.ScoreTorrents(candidates), nil
 }
 
-// SearchVideoTorrentList will find the first torrent in the list for each video quality, that also match the given filters.
+// SearchVideoTorrentList will find the torrent with the best score in the list for each video quality, that also match the given filters.
 // Since it returns one torrent for each known quality, the MinQuality and MaxQuality of the given filters are ignored.
 // Returns nil if none are found.
 func SearchVideoTorrentList(torrents []Torrent, filters SearchFilters) (map[VideoQuality]*Torrent, error) {
 
+	filters = filters.withDefaultExcludedReleases()
+
+	if err := filters.CompileFilter(); err != nil {
+		return nil, err
+	}
+
 	trnts := make(map[VideoQuality]*Torrent)
 
 	fetch := func(q VideoQuality) error {
@@ -245,7 +564,7 @@ func SearchVideoTorrentList(torrents []Torrent, filters SearchFilters) (map[Vide
 		filters.MinQuality = q
 		filters.MaxQuality = q
 
-		torrent, err := SearchTorrentList(torrents, filters)
+		torrent, err := searchBestTorrent(torrents, filters)
 
 		if err == nil && torrent != nil {
 			trnts[q] = torrent
@@ -275,13 +594,39 @@ func SearchVideoTorrentList(torrents []Torrent, filters SearchFilters) (map[Vide
 	return trnts, err
 }
 
+// searchBestTorrent returns the torrent in the list with the best score that matches the given filters and has at least
+// one seeder. Among torrents with the same score the first one in the list is returned, returning nil if none is found.
+func searchBestTorrent(torrents []Torrent, filters SearchFilters) (*Torrent, error) {
+
+	var candidates []Torrent
+
+	for i := range torrents {
+
+		if torrents[i].MaybeSeeded() && filters.IsOk(&torrents[i]) {
+			candidates = append(candidates, torrents[i])
+		}
+	}
+
+	if len(candidates) == 0 {
+
+		// Fall back to torrents without seeders, so that they can still be listed.
+		return SearchTorrentList(torrents, filters)
+	}
+
+	return &filters.ScoreTorrents(candidates)[0].Torrent, nil
+}
+
 // SearchTorrentList will return the first torrent in the list that matches the given filters, returning nil if none is found.
 func SearchTorrentList(torrents []Torrent, filters SearchFilters) (*Torrent, error) {
 
-	for _, t := range torrents {
+	// An invalid filter expression rejects every torrent.
+	filters.CompileFilter()
+
+	for i := range torrents {
 
-		if filters.IsOk(&t) {
+		if filters.IsOk(&torrents[i]) {
 
+			t := torrents[i]
 			return &t, nil
 
 		}
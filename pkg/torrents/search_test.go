@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -174,6 +175,191 @@ func TestUploaderOk(t *testing.T) {
 	}
 }
 
+func TestReleaseOk(t *testing.T) {
+
+	table := []struct {
+		in      SearchFilters
+		release VideoRelease
+		out     bool
+	}{
+		{SearchFilters{}, Cam, true},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Blacklist: DefaultExcludedReleases}}, Cam, false},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Blacklist: DefaultExcludedReleases}}, WEBDL, true},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Blacklist: DefaultExcludedReleases}}, "", true},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Blacklist: []VideoRelease{"TS"}}}, Telesync, false},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{"BluRay"}}}, BDRip, true},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{"BluRay"}}}, WEBDL, false},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{"BluRay"}}}, "", false},
+		{SearchFilters{MinRelease: WEBRip}, WEBDL, true},
+		{SearchFilters{MinRelease: WEBRip}, WEBRip, true},
+		{SearchFilters{MinRelease: WEBRip}, TVRip, false},
+		{SearchFilters{MinRelease: WEBRip}, "", true},
+	}
+
+	for _, tt := range table {
+		t.Run(fmt.Sprintf("%v %v", tt.in.MinRelease, tt.release), func(t *testing.T) {
+
+			if ok := tt.in.ReleaseOk(tt.release); ok != tt.out {
+				t.Errorf("got %v, want %v for %+v", ok, tt.out, tt.in.ReleaseTypes)
+			}
+		})
+	}
+}
+
+func TestSearchTermsOk(t *testing.T) {
+
+	table := []struct {
+		title string
+		terms []string
+		out   bool
+	}{
+		{"[Group] One Piece - 107 [1080p]", []string{"one piece 107"}, true},
+		{"[Group] One Piece - 1071 [1080p]", []string{"one piece 107"}, false},
+		{"[Group] One Piece - 1071 [1080p]", []string{"one piece 1071"}, true},
+		{"[Group] One Piece - 7 [720p]", []string{"one piece 07"}, true},
+		{"[Group] Made in Abyss - 010 [1080p]", []string{"made in abyss 01"}, false},
+		{"[Group] Made in Abyss - 01-12 [1080p]", []string{"made in abyss 01"}, false},
+		{"[Group] Made in Abyss - 01 (2017) [1080p]", []string{"made in abyss 01"}, true},
+		{"The.Expanse.S03E07.720p.HDTV", []string{"the expanse", "S03E07"}, true},
+		{"The.Expanse.S03E07E08.720p.HDTV", []string{"the expanse", "S03E07"}, true},
+		{"The.Expanse.S03E71.720p.HDTV", []string{"the expanse", "S03E07"}, false},
+		{"The Expanse Season 1 Complete", []string{"the expanse", "Season 1"}, true},
+		{"The Expanse Season 10 Complete", []string{"the expanse", "Season 1"}, false},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			filters := SearchFilters{SearchTerms: tt.terms}
+
+			if ok := filters.IsOk(&Torrent{Title: tt.title}); ok != tt.out {
+				t.Errorf("got %v, want %v for %v", ok, tt.out, tt.terms)
+			}
+
+			if err := filters.CompileFilter(); err != nil {
+				t.Fatal(err)
+			}
+
+			if ok := filters.IsOk(&Torrent{Title: tt.title}); ok != tt.out {
+				t.Errorf("got %v, want %v for %v when compiled", ok, tt.out, tt.terms)
+			}
+		})
+	}
+}
+
+func TestAgeOk(t *testing.T) {
+
+	now := time.Now()
+
+	table := []struct {
+		in       SearchFilters
+		uploaded time.Time
+		out      bool
+	}{
+		{SearchFilters{}, now, true},
+		{SearchFilters{MinAge: "2h"}, now.Add(-30 * time.Minute), false},
+		{SearchFilters{MinAge: "2h"}, now.Add(-3 * time.Hour), true},
+		{SearchFilters{MaxAge: "1y"}, now.AddDate(-2, 0, 0), false},
+		{SearchFilters{MaxAge: "1y"}, now.AddDate(0, -6, 0), true},
+		{SearchFilters{MinAge: "1d", MaxAge: "2w"}, now.AddDate(0, 0, -7), true},
+		{SearchFilters{MinAge: "1d", MaxAge: "2w"}, now.AddDate(0, 0, -21), false},
+		{SearchFilters{MinAge: "2h", MaxAge: "1y"}, time.Time{}, true},
+	}
+
+	for _, tt := range table {
+		t.Run(fmt.Sprintf("%v %v", tt.in.MinAge, tt.in.MaxAge), func(t *testing.T) {
+
+			if ok := tt.in.AgeOk(tt.uploaded); ok != tt.out {
+				t.Errorf("got %v, want %v for %v", ok, tt.out, tt.uploaded)
+			}
+		})
+	}
+
+	if _, err := (SearchFilters{MaxAge: "a year"}).MaxAgeDuration(); err == nil {
+		t.Errorf("expected an error for an invalid age")
+	}
+}
+
+func TestSizePerMinuteOk(t *testing.T) {
+
+	filters := SearchFilters{MinSizePerMinute: 20, MaxSizePerMinute: 80, Runtime: 25 * time.Minute}
+
+	table := []struct {
+		title   string
+		size    int64
+		runtime time.Duration
+		out     bool
+	}{
+		{"Some.Show.S01E01.1080p.WEB", 1000000, 0, true},
+		{"Some.Show.S01E01.1080p.WEB", 0, 25 * time.Minute, true},
+		{"Some.Show.S01E01.1080p.WEB", 1000000, 25 * time.Minute, true},
+		{"Some.Show.S01E01.1080p.WEB", 400000, 25 * time.Minute, false},
+		{"Some.Show.S01E01.1080p.WEB", 2500000, 25 * time.Minute, false},
+		{"Some.Show.S01E01E02.1080p.WEB", 2500000, 25 * time.Minute, true},
+		{"Some.Show.S01.1080p.WEB", 20000000, 25 * time.Minute, true},
+		{"Some.Movie.2019.1080p.BluRay", 2500000, 180 * time.Minute, false},
+		{"Some.Movie.2019.1080p.BluRay", 8000000, 180 * time.Minute, true},
+	}
+
+	for _, tt := range table {
+		t.Run(fmt.Sprintf("%v %v", tt.title, tt.size), func(t *testing.T) {
+
+			filters.Runtime = tt.runtime
+
+			if ok := filters.SizePerMinuteOk(&Torrent{Title: tt.title, Size: tt.size}); ok != tt.out {
+				t.Errorf("got %v, want %v", ok, tt.out)
+			}
+		})
+	}
+}
+
+func TestPickVideoTorrentRelease(t *testing.T) {
+
+	torrents := []Torrent{
+		{Title: "Some.Movie.2019.1080p.CAM", Seeders: 100, VideoQuality: High},
+		{Title: "Some.Movie.2019.1080p.WEBRip", Seeders: 50, VideoQuality: High},
+		{Title: "Some.Movie.2019.1080p.BluRay", Seeders: 10, VideoQuality: High},
+		{Title: "Some.Movie.2019.1080p.BluRay.x265", Seeders: 5, VideoQuality: High},
+		{Title: "Some.Movie.2019.1080p.WEB-DL", Seeders: 0, VideoQuality: High},
+	}
+
+	table := []struct {
+		filters SearchFilters
+		out     string
+	}{
+		{SearchFilters{}, "Some.Movie.2019.1080p.BluRay"},
+		{SearchFilters{MinRelease: WEBDL, ReleaseTypes: ReleaseTypeFilters{Blacklist: []VideoRelease{BDRip}}}, ""},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{Cam, WEBRip}}}, "Some.Movie.2019.1080p.WEBRip"},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{Cam}}}, ""},
+		{SearchFilters{ReleaseTypes: ReleaseTypeFilters{Whitelist: []VideoRelease{Cam}, Blacklist: []VideoRelease{}}}, "Some.Movie.2019.1080p.CAM"},
+	}
+
+	// The default blacklist does not apply to plain searches.
+	if filtered := (SearchFilters{}).FilterTorrents(torrents); len(filtered) != len(torrents) {
+		t.Errorf("got %v torrents, want all %v", len(filtered), len(torrents))
+	}
+
+	for _, tt := range table {
+		t.Run(tt.out, func(t *testing.T) {
+
+			torrent, err := PickVideoTorrent(torrents, tt.filters)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			title := ""
+			if torrent != nil {
+				title = torrent.Title
+			}
+
+			if title != tt.out {
+				t.Errorf("got %v, want %v", title, tt.out)
+			}
+		})
+	}
+}
+
 func TestFilterTorrentList(t *testing.T) {
 
 	torrentList, err := OpenTestSample("../../test_samples/piratebay_search.html")
@@ -0,0 +1,239 @@
+package torrents
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultSource is the name of the source used when none have been enabled in the configuration.
+const DefaultSource = "piratebay"
+
+// Source is a provider of torrents, such as The Pirate Bay or an indexer API.
+type Source interface {
+	// Name returns the name under which the source is registered.
+	Name() string
+
+	// Search queries the source for torrents matching the given query.
+	Search(query string) ([]Torrent, error)
+}
+
+// MovieSource is implemented by sources which can look up the torrents of a movie directly by its IMDb ID.
+type MovieSource interface {
+	Source
+
+	// SearchMovie queries the source for torrents of the movie with the given IMDb ID, in the tt0000000 format.
+	SearchMovie(imdbID string) ([]Torrent, error)
+}
+
+// EpisodeSource is implemented by sources which can look up the torrents of a series' episode directly
+// by the IMDb ID of the series.
+type EpisodeSource interface {
+	Source
+
+	// SearchEpisode queries the source for torrents of the given episode of the series with the given IMDb ID,
+	// in the tt0000000 format.
+	SearchEpisode(imdbID string, episode EpisodeQuery) ([]Torrent, error)
+}
+
+// EpisodeQuery identifies an episode of a series by its season and episode numbers.
+// An episode number of 0 refers to the whole season.
+type EpisodeQuery struct {
+	Season  uint
+	Episode uint
+}
+
+// SearchTerm returns the substring that should exist in the title of a torrent for this episode,
+// or an empty string if it refers to the whole series.
+func (q EpisodeQuery) SearchTerm() string {
+
+	if q.Season == 0 && q.Episode == 0 {
+		return ""
+	} else if q.Episode == 0 {
+		return fmt.Sprintf("Season %d", q.Season)
+	}
+
+	return fmt.Sprintf("S%02dE%02d", q.Season, q.Episode)
+}
+
+// Matches returns true if the given season and episode numbers belong to this episode.
+func (q EpisodeQuery) Matches(season uint, episode uint) bool {
+
+	return (q.Season == 0 || q.Season == season) &&
+		(q.Episode == 0 || q.Episode == episode)
+}
+
+// SourceFactory initializes a torrent source, given the filters of the search it will be used for.
+type SourceFactory func(filters SearchFilters) (Source, error)
+
+// SourceConfig holds the configuration of the torrent sources.
+type SourceConfig struct {
+	// Enabled holds the names of the sources to search, in order of preference.
+	Enabled []string `toml:"enabled"`
+
+	// Torznab holds the indexers searched by the torznab source.
+	Torznab []TorznabIndexer `toml:"torznab"`
+
+	// YTS holds the configuration of the yts source.
+	YTS YTSConfig `toml:"yts"`
+
+	// EZTV holds the configuration of the eztv source.
+	EZTV EZTVConfig `toml:"eztv"`
+
+	// Nyaa holds the configuration of the nyaa source.
+	Nyaa NyaaConfig `toml:"nyaa"`
+
+	// Feeds holds the URLs of the RSS or Atom feeds read by the feeds source.
+	Feeds []string `toml:"feeds"`
+}
+
+var sourceFactories = map[string]SourceFactory{}
+
+// RegisterSource makes a torrent source available under the given name, so that it can be enabled
+// through the configuration. Registering the same name twice replaces the previous factory.
+func RegisterSource(name string, factory SourceFactory) {
+
+	sourceFactories[strings.ToLower(name)] = factory
+}
+
+// RegisteredSources returns the names of all the available torrent sources, sorted alphabetically.
+func RegisteredSources() []string {
+
+	var names []string
+
+	for name := range sourceFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewSource initializes the torrent source registered under the given name.
+func NewSource(name string, filters SearchFilters) (Source, error) {
+
+	factory, exists := sourceFactories[strings.ToLower(strings.TrimSpace(name))]
+
+	if !exists {
+		return nil, fmt.Errorf("unknown torrent source: %v (available: %v)", name, strings.Join(RegisteredSources(), ", "))
+	}
+
+	return factory(filters)
+}
+
+// GetSources initializes the sources enabled in the filters, in the order in which they are specified.
+// If no sources are enabled, only the DefaultSource is returned.
+func (f SearchFilters) GetSources() ([]Source, error) {
+
+	names := f.Sources.Enabled
+
+	if len(names) == 0 {
+		names = []string{DefaultSource}
+	}
+
+	var sources []Source
+
+	for _, name := range names {
+
+		source, err := NewSource(name, f)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// SearchSources searches all of the given sources concurrently and merges their results into a single list,
+// without duplicates and sorted by seeders. Among torrents with equal seeders, the ones from sources earlier in the list come first.
+// An error is only returned if none of the sources yielded any results.
+func SearchSources(sources []Source, query string) ([]Torrent, error) {
+
+	return searchSources(sources, func(source Source) ([]Torrent, error) {
+		return source.Search(query)
+	})
+}
+
+// SearchMovieSources functions like SearchSources, but sources that implement MovieSource are searched
+// using the movie's IMDb ID instead of the query.
+func SearchMovieSources(sources []Source, imdbID string, query string) ([]Torrent, error) {
+
+	return searchSources(sources, func(source Source) ([]Torrent, error) {
+
+		if movieSource, ok := source.(MovieSource); ok {
+			return movieSource.SearchMovie(imdbID)
+		}
+
+		return source.Search(query)
+	})
+}
+
+// SearchEpisodeSources functions like SearchSources, but sources that implement EpisodeSource are searched
+// using the series' IMDb ID and the episode numbers instead of the query.
+func SearchEpisodeSources(sources []Source, imdbID string, episode EpisodeQuery, query string) ([]Torrent, error) {
+
+	return searchSources(sources, func(source Source) ([]Torrent, error) {
+
+		if episodeSource, ok := source.(EpisodeSource); ok {
+			return episodeSource.SearchEpisode(imdbID, episode)
+		}
+
+		return source.Search(query)
+	})
+}
+
+func searchSources(sources []Source, search func(Source) ([]Torrent, error)) ([]Torrent, error) {
+
+	type sourceResponse struct {
+		torrents []Torrent
+		err      error
+	}
+
+	responses := make([]chan sourceResponse, len(sources))
+
+	for i, source := range sources {
+
+		responses[i] = make(chan sourceResponse, 1)
+
+		go func(source Source, channel chan sourceResponse) {
+
+			torrents, err := search(source)
+
+			if os.Getenv("GOIRATE_DEBUG") == "true" {
+				log.Printf("%v -> %v, %v\n", source.Name(), len(torrents), err)
+			}
+
+			channel <- sourceResponse{torrents, err}
+
+		}(source, responses[i])
+	}
+
+	var allTorrents []Torrent
+	var lastErr error
+
+	for _, channel := range responses {
+
+		resp := <-channel
+
+		if resp.err != nil {
+			lastErr = resp.err
+		}
+
+		allTorrents = append(allTorrents, resp.torrents...)
+	}
+
+	allTorrents = DeduplicateTorrents(allTorrents)
+	sort.Stable(sortBySeeds(allTorrents))
+
+	if len(allTorrents) > 0 {
+		return allTorrents, nil
+	}
+
+	return allTorrents, lastErr
+}
@@ -0,0 +1,101 @@
+package torrents
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type staticSource struct {
+	name     string
+	torrents []Torrent
+	err      error
+}
+
+func (s *staticSource) Name() string {
+	return s.name
+}
+
+func (s *staticSource) Search(query string) ([]Torrent, error) {
+	return s.torrents, s.err
+}
+
+func TestGetSources(t *testing.T) {
+
+	RegisterSource("static", func(filters SearchFilters) (Source, error) {
+		return &staticSource{name: "static"}, nil
+	})
+
+	table := []struct {
+		in       []string
+		out      []string
+		outError bool
+	}{
+		{nil, []string{"piratebay"}, false},
+		{[]string{"static", "piratebay"}, []string{"static", "piratebay"}, false},
+		{[]string{" Static "}, []string{"static"}, false},
+		{[]string{"static", "nonexistent"}, nil, true},
+	}
+
+	for _, tt := range table {
+		t.Run(fmt.Sprint(tt.in), func(t *testing.T) {
+
+			filters := SearchFilters{Sources: SourceConfig{Enabled: tt.in}}
+
+			sources, err := filters.GetSources()
+
+			if (err != nil) != tt.outError {
+				t.Errorf("got error %v, want error %v", err, tt.outError)
+			}
+
+			var names []string
+			for _, source := range sources {
+				names = append(names, source.Name())
+			}
+
+			if !reflect.DeepEqual(names, tt.out) {
+				t.Errorf("got %v, want %v", names, tt.out)
+			}
+		})
+	}
+}
+
+func TestSearchSources(t *testing.T) {
+
+	first := &staticSource{name: "first", torrents: []Torrent{{Title: "a", Seeders: 5}, {Title: "b", Seeders: 10}}}
+	second := &staticSource{name: "second", torrents: []Torrent{{Title: "c", Seeders: 5}}}
+	failing := &staticSource{name: "failing", err: errors.New("unreachable")}
+
+	table := []struct {
+		name     string
+		in       []Source
+		out      []string
+		outError bool
+	}{
+		{"merge", []Source{first, second}, []string{"b", "a", "c"}, false},
+		{"order", []Source{second, first}, []string{"b", "c", "a"}, false},
+		{"partial failure", []Source{failing, second}, []string{"c"}, false},
+		{"total failure", []Source{failing}, nil, true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+
+			torrents, err := SearchSources(tt.in, "query")
+
+			if (err != nil) != tt.outError {
+				t.Errorf("got error %v, want error %v", err, tt.outError)
+			}
+
+			var titles []string
+			for _, torrent := range torrents {
+				titles = append(titles, torrent.Title)
+			}
+
+			if !reflect.DeepEqual(titles, tt.out) {
+				t.Errorf("got %v, want %v", titles, tt.out)
+			}
+		})
+	}
+}
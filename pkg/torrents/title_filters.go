@@ -0,0 +1,122 @@
+package torrents
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+// TitleFilters holds filters regarding the words and patterns in a torrent's title.
+// Words are compared against the normalized title, while patterns are case-insensitive regular expressions.
+type TitleFilters struct {
+	MustContain    []string `toml:"must_contain" json:"must_contain,omitempty"`
+	MustNotContain []string `toml:"must_not_contain" json:"must_not_contain,omitempty"`
+	MustMatch      []string `toml:"must_match" json:"must_match,omitempty"`
+	MustNotMatch   []string `toml:"must_not_match" json:"must_not_match,omitempty"`
+
+	// patterns holds the compiled patterns, set by Validate.
+	patterns map[string]*regexp.Regexp
+}
+
+// IsOk returns true if the given title contains all of the required words and matches all of the required patterns,
+// while containing none of the ignored words and matching none of the ignored patterns.
+// Titles are rejected if any of the patterns is invalid.
+func (f TitleFilters) IsOk(title string) bool {
+
+	normalized := " " + utils.NormalizeQuery(title) + " "
+
+	contains := func(word string) bool {
+		return strings.Contains(normalized, " "+utils.NormalizeQuery(word)+" ")
+	}
+
+	for _, word := range f.MustContain {
+		if !contains(word) {
+			return false
+		}
+	}
+
+	for _, word := range f.MustNotContain {
+		if contains(word) {
+			return false
+		}
+	}
+
+	for _, pattern := range f.MustMatch {
+		if r, err := f.pattern(pattern); err != nil || !r.MatchString(title) {
+			return false
+		}
+	}
+
+	for _, pattern := range f.MustNotMatch {
+		if r, err := f.pattern(pattern); err != nil || r.MatchString(title) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate returns an error if any of the patterns is not a valid regular expression.
+// The patterns are compiled once, so that they are not compiled again for every title that is checked.
+func (f *TitleFilters) Validate() error {
+
+	patterns := make(map[string]*regexp.Regexp)
+
+	for _, pattern := range append(append([]string{}, f.MustMatch...), f.MustNotMatch...) {
+
+		r, err := compileTitlePattern(pattern)
+
+		if err != nil {
+			return err
+		}
+
+		patterns[pattern] = r
+	}
+
+	f.patterns = patterns
+
+	return nil
+}
+
+// Merge returns the filters combined with the given ones, with the values of both lists added together.
+func (f TitleFilters) Merge(other TitleFilters) TitleFilters {
+
+	merge := func(a []string, b []string) []string {
+
+		if len(a)+len(b) == 0 {
+			return nil
+		}
+
+		return append(append([]string{}, a...), b...)
+	}
+
+	return TitleFilters{
+		MustContain:    merge(f.MustContain, other.MustContain),
+		MustNotContain: merge(f.MustNotContain, other.MustNotContain),
+		MustMatch:      merge(f.MustMatch, other.MustMatch),
+		MustNotMatch:   merge(f.MustNotMatch, other.MustNotMatch),
+	}
+}
+
+// pattern returns the compiled pattern, which is only compiled if it has not been compiled by Validate already.
+func (f TitleFilters) pattern(pattern string) (*regexp.Regexp, error) {
+
+	if r, ok := f.patterns[pattern]; ok {
+		return r, nil
+	}
+
+	return compileTitlePattern(pattern)
+}
+
+func compileTitlePattern(pattern string) (*regexp.Regexp, error) {
+
+	r, err := regexp.Compile(`(?i)` + pattern)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid title pattern %q: %v", pattern, err)
+	}
+
+	return r, nil
+}
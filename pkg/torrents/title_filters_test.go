@@ -0,0 +1,94 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTitleFiltersIsOk(t *testing.T) {
+
+	us := "The.Office.US.S09E23.720p.HDTV.x264-GROUP"
+	uk := "The Office (UK) S02E06 DVDRip XviD"
+	spinoff := "The.Office.Superfan.Episodes.S01E01.1080p.WEB.h264"
+
+	table := []struct {
+		filters TitleFilters
+		title   string
+		out     bool
+	}{
+		{TitleFilters{}, uk, true},
+		{TitleFilters{MustContain: []string{"US"}}, us, true},
+		{TitleFilters{MustContain: []string{"US"}}, uk, false},
+		{TitleFilters{MustContain: []string{"us"}}, spinoff, false},
+		{TitleFilters{MustNotContain: []string{"UK"}}, uk, false},
+		{TitleFilters{MustNotContain: []string{"UK"}}, us, true},
+		{TitleFilters{MustNotContain: []string{"superfan episodes"}}, spinoff, false},
+		{TitleFilters{MustMatch: []string{`^the office us s\d+e\d+`}}, us, false},
+		{TitleFilters{MustMatch: []string{`^the\.office\.us\.s\d+e\d+`}}, us, true},
+		{TitleFilters{MustMatch: []string{`^the\.office\.us\.s\d+e\d+`}}, spinoff, false},
+		{TitleFilters{MustNotMatch: []string{`superfan|\(uk\)`}}, uk, false},
+		{TitleFilters{MustNotMatch: []string{`superfan|\(uk\)`}}, us, true},
+		{TitleFilters{MustMatch: []string{`(`}}, us, false},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			if ok := tt.filters.IsOk(tt.title); ok != tt.out {
+				t.Errorf("got %v, want %v for %+v", ok, tt.out, tt.filters)
+			}
+		})
+	}
+}
+
+func TestTitleFiltersValidate(t *testing.T) {
+
+	valid := TitleFilters{MustMatch: []string{`s\d+`}, MustNotMatch: []string{`uk`}}
+
+	if err := valid.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if len(valid.patterns) != 2 {
+		t.Errorf("got %v compiled patterns, want 2", len(valid.patterns))
+	}
+
+	if !valid.IsOk("Show S01E01 US") || valid.IsOk("Show S01E01 UK") {
+		t.Errorf("the compiled patterns do not match as expected")
+	}
+
+	invalid := TitleFilters{MustNotMatch: []string{`[`}}
+
+	if err := invalid.Validate(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestTitleFiltersMerge(t *testing.T) {
+
+	a := TitleFilters{MustContain: []string{"US"}}
+	b := TitleFilters{MustContain: []string{"2005"}, MustNotMatch: []string{"uk"}}
+
+	expected := TitleFilters{
+		MustContain:  []string{"US", "2005"},
+		MustNotMatch: []string{"uk"},
+	}
+
+	if merged := a.Merge(b); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("got %+v, want %+v", merged, expected)
+	}
+}
+
+func TestSearchFiltersTitle(t *testing.T) {
+
+	filters := SearchFilters{
+		IMDbID: "tt0386676",
+		Title:  TitleFilters{MustNotContain: []string{"UK"}},
+	}
+
+	torrent := Torrent{Title: "The Office (UK) S02E06", IMDbID: "tt0386676"}
+
+	if filters.IsOk(&torrent) {
+		t.Errorf("title filters should apply to torrents matched by their IMDb ID")
+	}
+}
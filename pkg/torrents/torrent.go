@@ -14,6 +14,7 @@ type Torrent struct {
 	Size             int64        `json:"size"` // In kilobytes
 	Seeders          int          `json:"seeders"`
 	Leeches          int          `json:"leeches"`
+	UnknownPeers     bool         `json:"unknown_peers,omitempty"` // The source does not report peers, such as most feeds.
 	VerifiedUploader bool         `json:"verified_uploader"`
 	VideoQuality     VideoQuality `json:"video_quality"`
 	VideoRelease     VideoRelease `json:"video_release"`
@@ -22,6 +23,19 @@ type Torrent struct {
 	Magnet           string       `json:"magnet"`
 	UploadTime       time.Time    `json:"upload_time"`
 	Uploader         string       `json:"uploader"`
+	IMDbID           string       `json:"imdb_id,omitempty"`
+
+	release *parsedRelease
+}
+
+// GetVideoRelease returns the release type of the torrent, extracting it from the title if the source did not.
+func (t Torrent) GetVideoRelease() VideoRelease {
+
+	if t.VideoRelease != "" {
+		return t.VideoRelease
+	}
+
+	return ExtractVideoRelease(t.Title)
 }
 
 // FullURL returns the absolute URL for this torrent, including the mirror it was scraped from.
@@ -39,13 +53,24 @@ func (t Torrent) FullURL() string {
 // PeersString returns a string representation of the torrent's connected peers
 // in the Seeds/Peers format.
 func (t Torrent) PeersString() string {
+	if t.UnknownPeers {
+		return "? / ?"
+	}
 	return fmt.Sprintf("%v / %v", t.Seeders, t.Seeders+t.Leeches)
 }
 
+// MaybeSeeded returns true if the torrent has at least one seeder, or if its source does not report peers.
+func (t Torrent) MaybeSeeded() bool {
+	return t.Seeders > 0 || t.UnknownPeers
+}
+
 // SizeString returns a formatted string representation of the torrent's file size.
 func (t Torrent) SizeString() string {
+	return formatSize(t.Size * 1000)
+}
+
+func formatSize(sizeBytes int64) string {
 	const unit = 1000
-	sizeBytes := t.Size * 1000
 	if sizeBytes < unit {
 		return fmt.Sprintf("%d B", sizeBytes)
 	}
@@ -57,18 +82,21 @@ func (t Torrent) SizeString() string {
 	return fmt.Sprintf("%.1f %cB", float64(sizeBytes)/float64(div), "KMGTPE"[exp])
 }
 
-// MarshalJSON will override the json marshalling process so as to include the torrent's full url and its size in human readable format.
+// MarshalJSON will override the json marshalling process so as to include the torrent's full url, its size in human readable format
+// and the information parsed from its title.
 func (t *Torrent) MarshalJSON() ([]byte, error) {
 	type Alias Torrent
 
 	return json.Marshal(&struct {
-		SizeString string `json:"size_string"`
-		URL        string `json:"url"`
+		SizeString  string      `json:"size_string"`
+		URL         string      `json:"url"`
+		ReleaseInfo ReleaseInfo `json:"release_info"`
 		*Alias
 	}{
-		SizeString: t.SizeString(),
-		URL:        t.FullURL(),
-		Alias:      (*Alias)(t),
+		SizeString:  t.SizeString(),
+		URL:         t.FullURL(),
+		ReleaseInfo: t.ReleaseInfo(),
+		Alias:       (*Alias)(t),
 	})
 }
 
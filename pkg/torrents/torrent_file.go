@@ -0,0 +1,261 @@
+package torrents
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"time"
+)
+
+// TorrentFile holds the metadata of a torrent, as it is parsed from a .torrent file.
+type TorrentFile struct {
+	Name         string             `json:"name"`
+	InfoHash     string             `json:"info_hash"`
+	PieceLength  int64              `json:"piece_length"`
+	PieceCount   int                `json:"piece_count"`
+	Private      bool               `json:"private"`
+	Announce     string             `json:"announce,omitempty"`
+	AnnounceList [][]string         `json:"announce_list,omitempty"`
+	Comment      string             `json:"comment,omitempty"`
+	CreatedBy    string             `json:"created_by,omitempty"`
+	CreationDate *time.Time         `json:"creation_date,omitempty"`
+	Files        []TorrentFileEntry `json:"files"`
+}
+
+// TorrentFileEntry is a single file contained in a torrent.
+type TorrentFileEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// LoadTorrentFile reads and parses the .torrent file at the given path.
+func LoadTorrentFile(filePath string) (*TorrentFile, error) {
+
+	data, err := ioutil.ReadFile(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTorrentFile(data)
+}
+
+// ParseTorrentFile parses the contents of a .torrent file.
+func ParseTorrentFile(data []byte) (*TorrentFile, error) {
+
+	decoder := bencodeDecoder{data: data}
+
+	value, err := decoder.decode()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.pos != len(data) {
+		return nil, fmt.Errorf("torrent file: trailing data at offset %v", decoder.pos)
+	}
+
+	root, ok := value.(map[string]interface{})
+
+	if !ok {
+		return nil, errors.New("torrent file: not a dictionary")
+	}
+
+	info, ok := root["info"].(map[string]interface{})
+
+	if !ok {
+		return nil, errors.New("torrent file: missing info dictionary")
+	}
+
+	infoHash := sha1.Sum(data[decoder.infoStart:decoder.infoEnd])
+
+	pieces, _ := info["pieces"].(string)
+	private, _ := info["private"].(int64)
+
+	torrentFile := TorrentFile{
+		Name:        bencodeString(info, "name"),
+		InfoHash:    hex.EncodeToString(infoHash[:]),
+		PieceLength: bencodeInt(info, "piece length"),
+		PieceCount:  len(pieces) / sha1.Size,
+		Private:     private == 1,
+		Announce:    bencodeString(root, "announce"),
+		Comment:     bencodeString(root, "comment"),
+		CreatedBy:   bencodeString(root, "created by"),
+	}
+
+	if torrentFile.PieceLength <= 0 {
+		return nil, errors.New("torrent file: invalid piece length")
+	}
+
+	if creationDate, ok := root["creation date"].(int64); ok {
+
+		tm := time.Unix(creationDate, 0).UTC()
+		torrentFile.CreationDate = &tm
+	}
+
+	if tiers, ok := root["announce-list"].([]interface{}); ok {
+
+		for _, tier := range tiers {
+
+			var trackers []string
+
+			if tierList, ok := tier.([]interface{}); ok {
+
+				for _, tracker := range tierList {
+
+					if trackerURL, ok := tracker.(string); ok {
+						trackers = append(trackers, trackerURL)
+					}
+				}
+			}
+
+			if len(trackers) > 0 {
+				torrentFile.AnnounceList = append(torrentFile.AnnounceList, trackers)
+			}
+		}
+	}
+
+	if files, ok := info["files"].([]interface{}); ok {
+
+		// Multi-file torrent, where each path is relative to a directory with the torrent's name.
+		for _, file := range files {
+
+			fileDict, ok := file.(map[string]interface{})
+
+			if !ok {
+				return nil, errors.New("torrent file: invalid file entry")
+			}
+
+			pathParts := []string{torrentFile.Name}
+
+			if pathList, ok := fileDict["path"].([]interface{}); ok {
+
+				for _, part := range pathList {
+
+					if partStr, ok := part.(string); ok {
+						pathParts = append(pathParts, partStr)
+					}
+				}
+			}
+
+			torrentFile.Files = append(torrentFile.Files, TorrentFileEntry{
+				Path: path.Join(pathParts...),
+				Size: bencodeInt(fileDict, "length"),
+			})
+		}
+
+	} else {
+
+		torrentFile.Files = []TorrentFileEntry{{
+			Path: torrentFile.Name,
+			Size: bencodeInt(info, "length"),
+		}}
+	}
+
+	return &torrentFile, nil
+}
+
+// TorrentFileFromMagnet returns the metadata of a torrent that is available in its magnet link,
+// which is limited to its name, info hash and trackers.
+func TorrentFileFromMagnet(link string) (*TorrentFile, error) {
+
+	magnet, err := ParseMagnet(link)
+
+	if err != nil {
+		return nil, err
+	}
+
+	torrentFile := TorrentFile{
+		Name:     magnet.Name,
+		InfoHash: magnet.InfoHash,
+	}
+
+	for _, tracker := range magnet.Trackers {
+		torrentFile.AnnounceList = append(torrentFile.AnnounceList, []string{tracker})
+	}
+
+	if magnet.Length > 0 {
+		torrentFile.Files = []TorrentFileEntry{{Path: magnet.Name, Size: magnet.Length}}
+	}
+
+	return &torrentFile, nil
+}
+
+// Magnet returns the magnet link of the torrent, including its trackers.
+func (tf TorrentFile) Magnet() Magnet {
+
+	magnet := Magnet{
+		InfoHash: tf.InfoHash,
+		Name:     tf.Name,
+		Length:   tf.Size(),
+	}
+
+	return magnet.WithTrackers(tf.Trackers()...)
+}
+
+// Size returns the total size of the files in the torrent in bytes.
+func (tf TorrentFile) Size() int64 {
+
+	var size int64
+
+	for _, file := range tf.Files {
+		size += file.Size
+	}
+
+	return size
+}
+
+// SizeString returns a formatted string representation of the total size of the torrent's files.
+func (tf TorrentFile) SizeString() string {
+	return formatSize(tf.Size())
+}
+
+// PieceLengthString returns a formatted string representation of the size of the torrent's pieces.
+func (tf TorrentFile) PieceLengthString() string {
+	return formatSize(tf.PieceLength)
+}
+
+// SizeString returns a formatted string representation of the file's size.
+func (entry TorrentFileEntry) SizeString() string {
+	return formatSize(entry.Size)
+}
+
+// Trackers returns the URLs of all the trackers of the torrent, without duplicates,
+// in the order in which they appear in the announce list.
+func (tf TorrentFile) Trackers() []string {
+
+	var trackers []string
+	seen := map[string]bool{}
+
+	add := func(tracker string) {
+		if tracker != "" && !seen[tracker] {
+			seen[tracker] = true
+			trackers = append(trackers, tracker)
+		}
+	}
+
+	add(tf.Announce)
+
+	for _, tier := range tf.AnnounceList {
+		for _, tracker := range tier {
+			add(tracker)
+		}
+	}
+
+	return trackers
+}
+
+func bencodeString(dict map[string]interface{}, key string) string {
+
+	value, _ := dict[key].(string)
+	return value
+}
+
+func bencodeInt(dict map[string]interface{}, key string) int64 {
+
+	value, _ := dict[key].(int64)
+	return value
+}
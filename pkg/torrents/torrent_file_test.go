@@ -0,0 +1,120 @@
+package torrents
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadTorrentFile(t *testing.T) {
+
+	table := []struct {
+		file        string
+		name        string
+		infoHash    string
+		pieceLength int64
+		pieceCount  int
+		private     bool
+		files       []TorrentFileEntry
+		trackers    []string
+	}{
+		{
+			"../../test_samples/single.torrent",
+			"The.Expanse.S03E07.720p.HDTV.x264-AVS.mkv",
+			"b540e8e33d6b92d234b701e0a4bd58188d9079ce",
+			524288, 3, false,
+			[]TorrentFileEntry{{"The.Expanse.S03E07.720p.HDTV.x264-AVS.mkv", 1073741824}},
+			[]string{"udp://tracker.opentrackr.org:1337/announce", "udp://tracker.openbittorrent.com:6969/announce"},
+		},
+		{
+			"../../test_samples/multi.torrent",
+			"Cast Away (2000) [1080p]",
+			"05c4d891be7fea2390907a7434fcef56538b8b19",
+			1048576, 2, true,
+			[]TorrentFileEntry{
+				{"Cast Away (2000) [1080p]/Cast.Away.2000.1080p.BluRay.x264-[YTS.AM].mp4", 2469606195},
+				{"Cast Away (2000) [1080p]/Subs/English.srt", 104235},
+			},
+			[]string{"http://tracker.example.org/announce"},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tf, err := LoadTorrentFile(tt.file)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tf.Name != tt.name || tf.InfoHash != tt.infoHash || tf.PieceLength != tt.pieceLength ||
+				tf.PieceCount != tt.pieceCount || tf.Private != tt.private {
+
+				t.Errorf("got %v", tf)
+			}
+
+			if !reflect.DeepEqual(tf.Files, tt.files) {
+				t.Errorf("got %v, want %v", tf.Files, tt.files)
+			}
+
+			if !reflect.DeepEqual(tf.Trackers(), tt.trackers) {
+				t.Errorf("got %v, want %v", tf.Trackers(), tt.trackers)
+			}
+		})
+	}
+
+	tf, _ := LoadTorrentFile("../../test_samples/single.torrent")
+
+	if tf.Comment != "Goirate test sample" || tf.CreatedBy != "mktorrent 1.1" ||
+		tf.CreationDate == nil || !tf.CreationDate.Equal(time.Unix(1527736365, 0)) || tf.SizeString() != "1.1 GB" {
+
+		t.Errorf("got %v", tf)
+	}
+}
+
+func TestParseTorrentFileErrors(t *testing.T) {
+
+	valid := "d4:infod6:lengthi1e4:name1:a12:piece lengthi16384e6:pieces0:ee"
+
+	if _, err := ParseTorrentFile([]byte(valid)); err != nil {
+		t.Fatal(err)
+	}
+
+	table := []string{
+		"", "i42e", "d8:announce3:urle", "d4:infoi1ee",
+		valid + "x",
+		"d4:infod6:lengthi1e4:name1:a12:piece lengthi0e6:pieces0:ee",
+		"d4:info9223372036854775807:e",
+	}
+
+	for _, in := range table {
+		t.Run(in, func(t *testing.T) {
+
+			if tf, err := ParseTorrentFile([]byte(in)); err == nil {
+				t.Errorf("expected error, got %v", tf)
+			}
+		})
+	}
+}
+
+func TestTorrentFileFromMagnet(t *testing.T) {
+
+	magnet := "magnet:?xt=urn:btih:B540E8E33D6B92D234B701E0A4BD58188D9079CE&dn=The.Expanse.S03E07&tr=udp%3A%2F%2Ftracker.opentrackr.org%3A1337&tr=udp%3A%2F%2Ftracker.cyberia.is%3A6969"
+
+	tf, err := TorrentFileFromMagnet(magnet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tf.InfoHash != "b540e8e33d6b92d234b701e0a4bd58188d9079ce" || tf.Name != "The.Expanse.S03E07" ||
+		!reflect.DeepEqual(tf.Trackers(), []string{"udp://tracker.opentrackr.org:1337", "udp://tracker.cyberia.is:6969"}) {
+
+		t.Errorf("got %v", tf)
+	}
+
+	if _, err := TorrentFileFromMagnet("https://example.org"); err == nil {
+		t.Errorf("expected error for non-magnet link")
+	}
+}
@@ -0,0 +1,267 @@
+package torrents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+const torznabTimeout = 10 * time.Second
+
+// TorznabIndexer holds the configuration of an indexer which serves the Torznab API, such as Jackett or Prowlarr.
+type TorznabIndexer struct {
+	Name       string `toml:"name"`
+	URL        string `toml:"url"`
+	APIKey     string `toml:"api_key"`
+	Categories []int  `toml:"categories"`
+	Trusted    bool   `toml:"trusted"`
+}
+
+// TorznabResponse represents the RSS document returned by a Torznab search.
+type TorznabResponse struct {
+	Channel struct {
+		Items []TorznabItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// TorznabItem represents a single torrent, as it is returned by a Torznab search.
+type TorznabItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	Link      string `xml:"link"`
+	Comments  string `xml:"comments"`
+	PubDate   string `xml:"pubDate"`
+	Size      int64  `xml:"size"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+	Attributes []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"attr"`
+}
+
+// torznabSource searches all of the configured Torznab indexers.
+type torznabSource struct {
+	indexers []TorznabIndexer
+	ctx      context.Context
+}
+
+// torznabIndexerSource wraps a single indexer, so that the indexers can be searched concurrently like any other source.
+type torznabIndexerSource struct {
+	TorznabIndexer
+	ctx context.Context
+}
+
+func init() {
+	RegisterSource("torznab", newTorznabSource)
+}
+
+func newTorznabSource(filters SearchFilters) (Source, error) {
+
+	if len(filters.Sources.Torznab) == 0 {
+		return nil, errors.New("the torznab source is enabled, but no indexers are configured")
+	}
+
+	return &torznabSource{filters.Sources.Torznab, filters.Context}, nil
+}
+
+func (s *torznabSource) Name() string {
+	return "torznab"
+}
+
+func (s *torznabSource) Search(query string) ([]Torrent, error) {
+
+	var sources []Source
+
+	for _, indexer := range s.indexers {
+		sources = append(sources, torznabIndexerSource{indexer, s.ctx})
+	}
+
+	return SearchSources(sources, query)
+}
+
+func (s torznabIndexerSource) Name() string {
+	return s.DisplayName()
+}
+
+func (s torznabIndexerSource) Search(query string) ([]Torrent, error) {
+	return s.SearchContext(s.ctx, query)
+}
+
+// DisplayName returns the configured name of the indexer, or its host if no name is set.
+func (indexer TorznabIndexer) DisplayName() string {
+
+	if indexer.Name != "" {
+		return indexer.Name
+	}
+
+	indexerURL, err := url.Parse(indexer.URL)
+
+	if err != nil {
+		return indexer.URL
+	}
+
+	return indexerURL.Host
+}
+
+// SearchURL returns the URL of the Torznab API call which searches the indexer for the given query.
+func (indexer TorznabIndexer) SearchURL(query string) (string, error) {
+
+	searchURL, err := url.Parse(indexer.URL)
+
+	if err != nil {
+		return "", err
+	}
+
+	queryBuilder := searchURL.Query()
+	queryBuilder.Set("t", "search")
+	queryBuilder.Set("q", utils.NormalizeQuery(query))
+
+	if indexer.APIKey != "" {
+		queryBuilder.Set("apikey", indexer.APIKey)
+	}
+
+	if len(indexer.Categories) > 0 {
+
+		var categories []string
+
+		for _, category := range indexer.Categories {
+			categories = append(categories, strconv.Itoa(category))
+		}
+
+		queryBuilder.Set("cat", strings.Join(categories, ","))
+	}
+
+	searchURL.RawQuery = queryBuilder.Encode()
+
+	return searchURL.String(), nil
+}
+
+// Search queries the indexer's Torznab API for torrents matching the given query.
+func (indexer TorznabIndexer) Search(query string) ([]Torrent, error) {
+
+	return indexer.SearchContext(context.Background(), query)
+}
+
+// SearchContext functions like Search, but the request is aborted once the given context is cancelled or expires.
+func (indexer TorznabIndexer) SearchContext(ctx context.Context, query string) ([]Torrent, error) {
+
+	searchURL, err := indexer.SearchURL(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := utils.HTTPClient{
+		Timeout:  torznabTimeout,
+		Category: utils.TorrentRequests,
+		Context:  ctx,
+	}
+
+	var response struct {
+		TorznabResponse
+		// Set when the API responds with an <error> document instead.
+		Code        *int   `xml:"code,attr"`
+		Description string `xml:"description,attr"`
+	}
+
+	if err = client.GetXML(searchURL, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != nil {
+		return nil, fmt.Errorf("torznab: %v -> %v (%v)", indexer.DisplayName(), *response.Code, response.Description)
+	}
+
+	return response.GetTorrents(indexer), nil
+}
+
+// GetTorrents converts the response from a Torznab indexer into a list of torrents.
+func (response TorznabResponse) GetTorrents(indexer TorznabIndexer) []Torrent {
+
+	var trnts []Torrent
+
+	for _, item := range response.Channel.Items {
+
+		trnts = append(trnts, item.GetTorrent(indexer))
+	}
+
+	return trnts
+}
+
+// GetTorrent converts a single Torznab result into a torrent.
+func (item TorznabItem) GetTorrent(indexer TorznabIndexer) Torrent {
+
+	seeders, _ := strconv.Atoi(item.Attribute("seeders"))
+	peers, _ := strconv.Atoi(item.Attribute("peers"))
+
+	leeches := peers - seeders
+	if leeches < 0 {
+		leeches = 0
+	}
+
+	sizeBytes := item.Size
+	if sizeBytes == 0 {
+		sizeBytes, _ = strconv.ParseInt(item.Attribute("size"), 10, 64)
+	}
+	if sizeBytes == 0 {
+		sizeBytes = item.Enclosure.Length
+	}
+
+	magnet := item.Attribute("magneturl")
+	if magnet == "" && strings.HasPrefix(item.Link, "magnet:") {
+		magnet = item.Link
+	}
+	if magnet == "" && item.Attribute("infohash") != "" {
+		magnet = NewMagnet(item.Attribute("infohash"), item.Title).String()
+	}
+
+	uploadTime, err := time.Parse(time.RFC1123Z, item.PubDate)
+	if err != nil {
+		uploadTime, _ = time.Parse(time.RFC1123, item.PubDate)
+	}
+
+	// The details page is preferred over the download link, since the latter usually contains the API key.
+	pageURL := item.Comments
+	if pageURL == "" {
+		pageURL = item.GUID
+	}
+
+	mirrorURL, torrentURL := splitTorrentURL(pageURL)
+
+	return Torrent{
+		Title:            item.Title,
+		Size:             sizeBytes / 1000,
+		Seeders:          seeders,
+		Leeches:          leeches,
+		VerifiedUploader: indexer.Trusted,
+		VideoQuality:     extractVideoQuality(item.Title),
+		VideoRelease:     ExtractVideoRelease(item.Title),
+		MirrorURL:        mirrorURL,
+		TorrentURL:       torrentURL,
+		Magnet:           magnet,
+		UploadTime:       uploadTime,
+		Uploader:         indexer.DisplayName(),
+	}
+}
+
+// Attribute returns the value of the torznab:attr element with the given name, or an empty string if it is missing.
+func (item TorznabItem) Attribute(name string) string {
+
+	for _, attr := range item.Attributes {
+
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+package torrents
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func torznabTestServer(t *testing.T) *httptest.Server {
+
+	return sampleServer(sampleRoute{
+		path:   "/",
+		sample: "torznab.xml",
+		match: func(query url.Values) bool {
+
+			if query.Get("apikey") != "secret" {
+				return false
+			}
+
+			if query.Get("t") != "search" || query.Get("q") != "the expanse s03e07" {
+				t.Errorf("unexpected query: %v", query.Encode())
+			}
+
+			return true
+		},
+		empty: `<?xml version="1.0" encoding="UTF-8"?><error code="100" description="Invalid API Key" />`,
+	})
+}
+
+func TestTorznabSearchURL(t *testing.T) {
+
+	table := []struct {
+		in  TorznabIndexer
+		out string
+	}{
+		{TorznabIndexer{URL: "http://localhost:9117/api"}, "http://localhost:9117/api?q=the+expanse&t=search"},
+		{TorznabIndexer{URL: "http://localhost:9117/api", APIKey: "key", Categories: []int{5000, 5040}}, "http://localhost:9117/api?apikey=key&cat=5000%2C5040&q=the+expanse&t=search"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.out, func(t *testing.T) {
+
+			s, err := tt.in.SearchURL("The Expanse")
+
+			if err != nil {
+				t.Error(err)
+			}
+
+			if s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
+
+func TestTorznabSearch(t *testing.T) {
+
+	server := torznabTestServer(t)
+	defer server.Close()
+
+	indexer := TorznabIndexer{Name: "jackett", URL: server.URL, APIKey: "secret", Trusted: true}
+
+	torrents, err := indexer.Search("The Expanse S03E07")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torrents) != 2 {
+		t.Fatalf("got %v torrents, want 2", len(torrents))
+	}
+
+	tor := torrents[0]
+
+	if tor.Title != "The.Expanse.S03E07.720p.HDTV.x264-AVS" || tor.Size != 1073741 || tor.Seeders != 120 || tor.Leeches != 30 ||
+		tor.VideoQuality != Medium || !tor.VerifiedUploader || tor.Uploader != "jackett" ||
+		!tor.UploadTime.Equal(time.Date(2018, time.May, 31, 3, 12, 45, 0, time.UTC)) {
+
+		t.Errorf("got %v", tor)
+	}
+
+	if !strings.Contains(tor.Magnet, "xt=urn:btih:8cd8a1a2e3b7c9f1d4a1b6e2a3c4d5e6f7a8b9c0") {
+		t.Errorf("got magnet %v", tor.Magnet)
+	}
+
+	if tor.FullURL() != "https://tracker.example.org/torrent/22274951/The.Expanse.S03E07.720p.HDTV.x264-AVS" {
+		t.Errorf("got url %v", tor.FullURL())
+	}
+
+	tor = torrents[1]
+
+	if tor.Size != 2147483 || tor.Leeches != 0 || tor.VideoQuality != High ||
+		!strings.HasPrefix(tor.Magnet, "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567") {
+
+		t.Errorf("got %v", tor)
+	}
+
+	if tor.FullURL() != "https://other.example.org/details.php?id=1234" {
+		t.Errorf("got url %v", tor.FullURL())
+	}
+}
+
+func TestTorznabSearchError(t *testing.T) {
+
+	server := torznabTestServer(t)
+	defer server.Close()
+
+	indexer := TorznabIndexer{URL: server.URL, APIKey: "wrong"}
+
+	_, err := indexer.Search("The Expanse S03E07")
+
+	if err == nil || !strings.Contains(err.Error(), "Invalid API Key") {
+		t.Errorf("expected API key error, got %v", err)
+	}
+}
+
+func TestTorznabSource(t *testing.T) {
+
+	server := torznabTestServer(t)
+	defer server.Close()
+
+	filters := SearchFilters{Sources: SourceConfig{
+		Enabled: []string{"torznab"},
+		Torznab: []TorznabIndexer{{URL: server.URL, APIKey: "secret"}, {URL: server.URL, APIKey: "wrong"}},
+	}}
+
+	torrents, err := filters.SearchTorrents("The Expanse S03E07")
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(torrents) != 2 || torrents[0].Seeders != 120 {
+		t.Errorf("got %v", torrents)
+	}
+
+	filters.Sources.Torznab = nil
+
+	if _, err := filters.GetSources(); err == nil {
+		t.Errorf("expected error for torznab source without indexers")
+	}
+}
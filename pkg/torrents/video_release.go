@@ -1,7 +1,6 @@
 package torrents
 
 import (
-	"sort"
 	"strings"
 )
 
@@ -81,36 +80,70 @@ var releaseLabels = map[VideoRelease][]string{
 	BDRip:     {"Blu-Ray", "BluRay", "BLURAY", "BDRip", "BRRip", "BDMV", "BDR", "BD25", "BD50", "BD5", "BD9", "BR-rip"},
 }
 
-// ExtractVideoRelease parses a torrent's title and returns its video release type, if it exists.
-func ExtractVideoRelease(torrentTitle string) VideoRelease {
+// releaseRanking orders the release types from the worst to the best, as far as the quality of the video is concerned.
+var releaseRanking = []VideoRelease{
+	Cam, Telesync, Workprint, Telecine, PPVRip, Screener, DDC, R5,
+	TVRip, VODRip, DVDRip, WEBCap, WEBRip, DVDR, WEBDL, BDRip,
+}
+
+// DefaultExcludedReleases holds the release types that are rejected when searching for video torrents,
+// unless a release blacklist has been configured, since they are recorded or leaked copies of a film.
+var DefaultExcludedReleases = []VideoRelease{Cam, Telesync, Workprint, Screener}
+
+// ParseVideoRelease returns the release type with the given name or label, such as Blu-ray or BDRip,
+// ignoring the case and separators. Returns an empty string if it matches none.
+func ParseVideoRelease(name string) VideoRelease {
 
-	torrentTitle = strings.ToLower(torrentTitle)
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer(" ", "", "-", "", ".", "", ",", "").Replace(s))
+	}
 
-	labelReleaseMap := map[string]VideoRelease{}
-	var sortedLaebels []string
+	name = normalize(name)
 
-	// Create a mapping of label -> release
-	for release, labels := range releaseLabels {
+	for _, release := range releaseRanking {
 
-		for _, label := range labels {
+		if normalize(string(release)) == name {
+			return release
+		}
 
-			labelReleaseMap[label] = release
-			sortedLaebels = append(sortedLaebels, label)
+		for _, label := range releaseLabels[release] {
+			if normalize(label) == name {
+				return release
+			}
 		}
 	}
 
-	// Sort labels in descending order
-	sort.Slice(sortedLaebels, func(i, j int) bool {
-		return len(sortedLaebels[i]) > len(sortedLaebels[j])
-	})
+	return ""
+}
 
-	for _, label := range sortedLaebels {
+// WorseThan will return true if the release type passed as an argument is
+// better than this one. Unknown release types rank below all others.
+func (r VideoRelease) WorseThan(release VideoRelease) bool {
+	return r.numeric() < release.numeric()
+}
 
-		if strings.Contains(torrentTitle, strings.ToLower(label)) {
+// BetterThan will return true if the release type passed as an argument is
+// worse than this one. Unknown release types rank below all others.
+func (r VideoRelease) BetterThan(release VideoRelease) bool {
+	return r.numeric() > release.numeric()
+}
+
+func (r VideoRelease) numeric() int {
+
+	release := ParseVideoRelease(string(r))
 
-			return labelReleaseMap[label]
+	for i, ranked := range releaseRanking {
+		if ranked == release {
+			return i + 1
 		}
 	}
 
-	return ""
+	return 0
+}
+
+// ExtractVideoRelease parses a torrent's title and returns its video release type, if it exists.
+// The labels of the release types are only matched as whole words, so that short ones like TS are not found inside other words.
+func ExtractVideoRelease(torrentTitle string) VideoRelease {
+
+	return VideoRelease(firstPatternValue(sourcePatterns, releaseSeparators.Replace(torrentTitle)))
 }
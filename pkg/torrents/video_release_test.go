@@ -24,3 +24,72 @@ func TestExtractVideoRelease(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractVideoReleaseWords(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out VideoRelease
+	}{
+		{"The Outsiders 1983 1080p", ""},
+		{"Some.Movie.2019.HDTS.x264", Telesync},
+		{"Some.Movie.2019.TS.x264", Telesync},
+		{"Some Movie 2019 1080p WEB-DL", WEBDL},
+		{"Some Movie 2019 1080p WEB DL", WEBDL},
+		{"Some.Movie.2019.1080p.WEB.x264", WEBRip},
+		{"Some.Movie.2019.R5.LINE.XviD", R5},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			if s := ExtractVideoRelease(tt.in); s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseVideoRelease(t *testing.T) {
+
+	table := []struct {
+		in  string
+		out VideoRelease
+	}{
+		{"Blu-ray", BDRip},
+		{"bluray", BDRip},
+		{"BDRip", BDRip},
+		{"WEB-DL", WEBDL},
+		{"webdl", WEBDL},
+		{"cam", Cam},
+		{"TS", Telesync},
+		{"HDTV", TVRip},
+		{"HDTV, PDTV or DSRip", TVRip},
+		{"nothing", ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			if s := ParseVideoRelease(tt.in); s != tt.out {
+				t.Errorf("got %v, want %v", s, tt.out)
+			}
+		})
+	}
+}
+
+func TestReleaseRanking(t *testing.T) {
+
+	var unknown VideoRelease
+
+	if !Cam.WorseThan(Telesync) ||
+		!Screener.WorseThan(WEBRip) ||
+		!WEBRip.WorseThan(WEBDL) ||
+		!WEBDL.WorseThan(BDRip) ||
+		!unknown.WorseThan(Cam) ||
+		BDRip.WorseThan(BDRip) ||
+		BDRip.BetterThan(BDRip) ||
+		!VideoRelease("bluray").BetterThan("web-dl") {
+		t.Errorf("Error with VideoRelease ranking")
+	}
+}
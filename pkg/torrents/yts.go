@@ -0,0 +1,175 @@
+package torrents
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gitlab.com/haath/goirate/pkg/utils"
+)
+
+const defaultYTSURL string = "https://yts.mx"
+
+const ytsTimeout = 10 * time.Second
+
+// YTSConfig holds the configuration of the YTS movie source.
+type YTSConfig struct {
+	URL string `toml:"url"`
+}
+
+// YTSAPIResponse represents the response returned by the list_movies endpoint of the YTS API.
+type YTSAPIResponse struct {
+	Status        string `json:"status"`
+	StatusMessage string `json:"status_message"`
+	Data          struct {
+		Movies []struct {
+			URL       string                  `json:"url"`
+			IMDbCode  string                  `json:"imdb_code"`
+			TitleLong string                  `json:"title_long"`
+			Runtime   int                     `json:"runtime"`
+			Torrents  []YTSAPIResponseTorrent `json:"torrents"`
+		} `json:"movies"`
+	} `json:"data"`
+}
+
+// YTSAPIResponseTorrent represents a single torrent of a movie, as it is returned by the YTS API.
+type YTSAPIResponseTorrent struct {
+	URL              string `json:"url"`
+	Hash             string `json:"hash"`
+	Quality          string `json:"quality"`
+	Type             string `json:"type"`
+	VideoCodec       string `json:"video_codec"`
+	Seeds            int    `json:"seeds"`
+	Peers            int    `json:"peers"`
+	SizeBytes        int64  `json:"size_bytes"`
+	DateUploadedUnix int64  `json:"date_uploaded_unix"`
+}
+
+// ytsSource searches a YTS-style JSON API, which lists one torrent per quality for each movie.
+type ytsSource struct {
+	url string
+	ctx context.Context
+}
+
+func init() {
+	RegisterSource("yts", newYTSSource)
+}
+
+func newYTSSource(filters SearchFilters) (Source, error) {
+
+	ytsURL := filters.Sources.YTS.URL
+
+	if ytsURL == "" {
+		ytsURL = defaultYTSURL
+	}
+
+	return &ytsSource{ytsURL, filters.Context}, nil
+}
+
+func (s *ytsSource) Name() string {
+	return "yts"
+}
+
+func (s *ytsSource) Search(query string) ([]Torrent, error) {
+
+	return s.listMovies(utils.NormalizeQuery(query))
+}
+
+func (s *ytsSource) SearchMovie(imdbID string) ([]Torrent, error) {
+
+	return s.listMovies(imdbID)
+}
+
+// SearchURL returns the URL of the API call that lists the movies matching the given query term,
+// which can be either a title or an IMDb ID.
+func (s *ytsSource) SearchURL(queryTerm string) (string, error) {
+
+	searchURL, err := url.Parse(s.url)
+
+	if err != nil {
+		return "", err
+	}
+
+	searchURL.Path = "/api/v2/list_movies.json"
+
+	queryBuilder := searchURL.Query()
+	queryBuilder.Set("query_term", queryTerm)
+	queryBuilder.Set("limit", "50")
+	searchURL.RawQuery = queryBuilder.Encode()
+
+	return searchURL.String(), nil
+}
+
+func (s *ytsSource) listMovies(queryTerm string) ([]Torrent, error) {
+
+	searchURL, err := s.SearchURL(queryTerm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := utils.HTTPClient{
+		Timeout:  ytsTimeout,
+		Category: utils.TorrentRequests,
+		Context:  s.ctx,
+	}
+
+	var response YTSAPIResponse
+
+	if err = client.GetJSON(searchURL, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Status != "ok" {
+		return nil, fmt.Errorf("yts: %v -> %v", searchURL, response.StatusMessage)
+	}
+
+	return response.GetTorrents(), nil
+}
+
+// GetTorrents converts the response from the YTS API into a list of torrents, one for each quality
+// that is available for every movie in the response.
+func (response YTSAPIResponse) GetTorrents() []Torrent {
+
+	var trnts []Torrent
+
+	for _, movie := range response.Data.Movies {
+
+		movieURL, _ := url.Parse(movie.URL)
+
+		for _, obj := range movie.Torrents {
+
+			// YTS does not name its torrents, so the title is assembled to resemble a release name.
+			title := fmt.Sprintf("%v [%v] [%v]", movie.TitleLong, obj.Quality, obj.Type)
+
+			if obj.VideoCodec != "" {
+				title = fmt.Sprintf("%v [%v]", title, obj.VideoCodec)
+			}
+
+			title += " [YTS]"
+
+			torrent := Torrent{
+				Title:        title,
+				Size:         obj.SizeBytes / 1000,
+				Seeders:      obj.Seeds,
+				Leeches:      obj.Peers,
+				VideoQuality: extractVideoQuality(obj.Quality),
+				VideoRelease: ExtractVideoRelease(obj.Type),
+				Magnet:       NewMagnet(obj.Hash, title).String(),
+				UploadTime:   time.Unix(obj.DateUploadedUnix, 0),
+				Uploader:     "YTS",
+				IMDbID:       movie.IMDbCode,
+			}
+
+			if movieURL != nil {
+				torrent.MirrorURL = fmt.Sprintf("%v://%v", movieURL.Scheme, movieURL.Host)
+				torrent.TorrentURL = movieURL.Path
+			}
+
+			trnts = append(trnts, torrent)
+		}
+	}
+
+	return trnts
+}
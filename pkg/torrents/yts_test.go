@@ -0,0 +1,110 @@
+package torrents
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func ytsTestServer() *httptest.Server {
+
+	return sampleServer(sampleRoute{
+		path:   "/api/v2/list_movies.json",
+		sample: "yts.json",
+		match: func(query url.Values) bool {
+			return query.Get("query_term") == "tt0162222"
+		},
+		empty: `{"status":"ok","status_message":"Query was successful","data":{"movie_count":0}}`,
+	})
+}
+
+func TestYTSSearchMovie(t *testing.T) {
+
+	server := ytsTestServer()
+	defer server.Close()
+
+	source, err := NewSource("yts", SearchFilters{Sources: SourceConfig{YTS: YTSConfig{URL: server.URL}}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	torrents, err := source.(MovieSource).SearchMovie("tt0162222")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := []struct {
+		title   string
+		quality VideoQuality
+		release VideoRelease
+		size    int64
+		seeders int
+		hash    string
+	}{
+		{"Cast Away (2000) [720p] [bluray] [x264] [YTS]", Medium, BDRip, 996325, 84, "66bbf4d7c9fb3f46abe1f8e8e7e1c7c1d6c0a1b1"},
+		{"Cast Away (2000) [1080p] [bluray] [x264] [YTS]", High, BDRip, 2469606, 210, "7c7e2a5f3b0d1e9a8c6b4d2f0e1a3c5b7d9f1e2a"},
+		{"Cast Away (2000) [2160p] [web] [x265] [YTS]", UHD, WEBRip, 5808945, 35, "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"},
+	}
+
+	if len(torrents) != len(table) {
+		t.Fatalf("got %v torrents, want %v", len(torrents), len(table))
+	}
+
+	for i, tt := range table {
+		t.Run(tt.title, func(t *testing.T) {
+
+			tor := torrents[i]
+
+			if tor.Title != tt.title || tor.VideoQuality != tt.quality || tor.VideoRelease != tt.release ||
+				tor.Size != tt.size || tor.Seeders != tt.seeders || tor.IMDbID != "tt0162222" || tor.VerifiedUploader {
+
+				t.Errorf("got %v, want %v", tor, tt)
+			}
+
+			if !strings.Contains(tor.Magnet, "xt=urn:btih:"+tt.hash) {
+				t.Errorf("got magnet %v", tor.Magnet)
+			}
+
+			if tor.FullURL() != "https://yts.mx/movies/cast-away-2000" {
+				t.Errorf("got url %v", tor.FullURL())
+			}
+		})
+	}
+}
+
+func TestYTSSearchByIMDbID(t *testing.T) {
+
+	server := ytsTestServer()
+	defer server.Close()
+
+	filters := SearchFilters{
+		Sources:     SourceConfig{Enabled: []string{"yts"}, YTS: YTSConfig{URL: server.URL}},
+		IMDbID:      "tt0162222",
+		SearchTerms: []string{"Seul au monde", "2000"},
+	}
+
+	torrents, err := filters.SearchVideoTorrents("Seul au monde 2000")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(torrents) != 3 {
+		t.Errorf("got %v, want one torrent per quality", torrents)
+	}
+
+	best, err := PickVideoTorrent(torrents, filters)
+
+	if err != nil || best == nil || best.VideoQuality != UHD {
+		t.Errorf("got %v, %v", best, err)
+	}
+
+	filters.IMDbID = "tt0000001"
+
+	if filtered := filters.FilterTorrents(torrents); len(filtered) != 0 {
+		t.Errorf("expected torrents of a different movie to be rejected, got %v", filtered)
+	}
+}
@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores the responses of GET requests on disk, so that they can be reused until they expire
+// and revalidated with the server afterwards, using their ETag or Last-Modified headers.
+type ResponseCache struct {
+	dir string
+}
+
+// CacheStats holds statistics of the responses stored in a cache.
+type CacheStats struct {
+	Entries int       `json:"entries"`
+	Expired int       `json:"expired"`
+	Size    int64     `json:"size"`
+	Oldest  time.Time `json:"oldest"`
+	Newest  time.Time `json:"newest"`
+}
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	Stored  time.Time   `json:"stored"`
+	Expires time.Time   `json:"expires"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+}
+
+var (
+	cacheMutex sync.RWMutex
+	cache      *ResponseCache
+)
+
+// NewResponseCache creates a cache which stores responses in the given directory.
+func NewResponseCache(dir string) *ResponseCache {
+
+	return &ResponseCache{dir: dir}
+}
+
+// SetCache sets the cache used by the library's requests which specify a CacheTTL.
+// A nil cache disables caching altogether.
+func SetCache(c *ResponseCache) {
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	cache = c
+}
+
+// GetCache returns the cache used by the library's requests, or nil if caching is disabled.
+func GetCache() *ResponseCache {
+
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+
+	return cache
+}
+
+// Clear deletes all of the responses stored in the cache.
+func (c *ResponseCache) Clear() error {
+
+	files, err := c.files()
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns statistics of the responses stored in the cache.
+func (c *ResponseCache) Stats() (CacheStats, error) {
+
+	var stats CacheStats
+
+	files, err := c.files()
+
+	if err != nil {
+		return stats, err
+	}
+
+	now := time.Now()
+
+	for _, file := range files {
+
+		info, err := os.Stat(file)
+
+		if err != nil {
+			continue
+		}
+
+		entry, err := loadCacheEntry(file)
+
+		if err != nil {
+			continue
+		}
+
+		stats.Entries++
+		stats.Size += info.Size()
+
+		if now.After(entry.Expires) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || entry.Stored.Before(stats.Oldest) {
+			stats.Oldest = entry.Stored
+		}
+		if entry.Stored.After(stats.Newest) {
+			stats.Newest = entry.Stored
+		}
+	}
+
+	return stats, nil
+}
+
+// do serves the request from the cache while its stored response is fresh. Otherwise it is sent,
+// asking the server to only respond with the body if it has changed since the stored response.
+// If the request fails, the stored response is served even if it has expired.
+func (c *ResponseCache) do(request *http.Request, ttl time.Duration, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+
+	file := c.path(request)
+	entry, _ := loadCacheEntry(file)
+
+	if entry != nil && time.Since(entry.Stored) < ttl {
+		return entry.response(request), nil
+	}
+
+	if entry != nil {
+
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			request.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	res, err := send(request)
+
+	if err != nil {
+
+		if entry != nil && request.Context().Err() == nil {
+
+			if os.Getenv("GOIRATE_DEBUG") == "true" {
+				log.Printf("serving an expired response for %v%v: %v\n", request.URL.Host, request.URL.Path, err)
+			}
+
+			return entry.response(request), nil
+		}
+
+		return nil, err
+	}
+
+	switch {
+
+	case res.StatusCode == http.StatusNotModified && entry != nil:
+
+		res.Body.Close()
+
+		// The stored response is still valid, but the server may have sent newer headers.
+		for _, name := range []string{"ETag", "Last-Modified"} {
+			if value := res.Header.Get(name); value != "" {
+				entry.Header.Set(name, value)
+			}
+		}
+
+	case res.StatusCode == http.StatusOK:
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry = &cacheEntry{Header: res.Header, Body: body}
+
+	default:
+
+		return res, nil
+	}
+
+	entry.Stored = time.Now()
+	entry.Expires = entry.Stored.Add(ttl)
+
+	if err := entry.save(file); err != nil && os.Getenv("GOIRATE_DEBUG") == "true" {
+		log.Printf("failed to cache the response for %v%v: %v\n", request.URL.Host, request.URL.Path, err)
+	}
+
+	return entry.response(request), nil
+}
+
+// path returns the file in which the response to the given request is stored.
+// The URL is hashed, so that API keys in it are not exposed in the names of the files.
+func (c *ResponseCache) path(request *http.Request) string {
+
+	hash := sha256.Sum256([]byte(request.Method + " " + request.URL.String()))
+
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *ResponseCache) files() ([]string, error) {
+
+	infos, err := ioutil.ReadDir(c.dir)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, info := range infos {
+
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
+			files = append(files, filepath.Join(c.dir, info.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+func loadCacheEntry(file string) (*cacheEntry, error) {
+
+	data, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	if entry.Header == nil {
+		entry.Header = http.Header{}
+	}
+
+	return &entry, nil
+}
+
+// save writes the entry to a temporary file first, so that concurrent requests never read a partially written entry.
+func (e *cacheEntry) save(file string) error {
+
+	data, err := json.Marshal(e)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".tmp-")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+func (e *cacheEntry) response(request *http.Request) *http.Response {
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "goirate-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	SetCache(NewResponseCache(dir))
+	defer SetCache(nil)
+
+	calls := 0
+	revalidations := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		calls++
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"title": "The Expanse"}`))
+	}))
+
+	get := func(ttl time.Duration) (string, error) {
+
+		var resp struct {
+			Title string `json:"title"`
+		}
+
+		client := HTTPClient{CacheTTL: ttl}
+		err := client.GetJSON(server.URL+"/series/280619", &resp)
+
+		return resp.Title, err
+	}
+
+	var tests = []struct {
+		name          string
+		ttl           time.Duration
+		calls         int
+		revalidations int
+	}{
+		{"fetched", time.Hour, 1, 0},
+		{"fresh", time.Hour, 1, 0},
+		{"revalidated", time.Nanosecond, 2, 1},
+		{"uncached", 0, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			title, err := get(tt.ttl)
+
+			if err != nil || title != "The Expanse" {
+				t.Errorf("got %v, %v, want The Expanse", title, err)
+			}
+
+			if calls != tt.calls || revalidations != tt.revalidations {
+				t.Errorf("got %v calls and %v revalidations, want %v and %v", calls, revalidations, tt.calls, tt.revalidations)
+			}
+		})
+	}
+
+	stats, err := GetCache().Stats()
+
+	if err != nil || stats.Entries != 1 {
+		t.Errorf("got %+v, %v, want 1 entry", stats, err)
+	}
+
+	server.Close()
+
+	if title, err := get(time.Nanosecond); err != nil || title != "The Expanse" {
+		t.Errorf("got %v, %v, want the expired response while the server is unreachable", title, err)
+	}
+
+	if err := GetCache().Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats, _ := GetCache().Stats(); stats.Entries != 0 {
+		t.Errorf("got %v entries after clearing the cache", stats.Entries)
+	}
+}
@@ -2,9 +2,13 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -18,19 +22,154 @@ type HTTPClient struct {
 	// Timeout is the time limit for requests.
 	Timeout   time.Duration
 	AuthToken string
+	// Category determines which of the configured proxies the requests go through.
+	Category RequestCategory
+	// Context is used to cancel the requests, for example when the command is interrupted.
+	// If it is nil, the requests can only be limited by the Timeout.
+	Context context.Context
+	// CacheTTL is the time for which the responses to GET requests are reused, when a cache has been set through SetCache.
+	CacheTTL time.Duration
+}
+
+// client returns an http.Client which goes through the proxy of the client's category,
+// and records or replays its traffic if set through SetReplay.
+func (c *HTTPClient) client() (*http.Client, error) {
+
+	transport, err := ProxyTransport(c.Category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   c.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// newRequest creates a request bound to the client's context.
+func (c *HTTPClient) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+
+	ctx := c.Context
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// do sends the request, or serves it from the cache if the client's requests are cached.
+// The cache is bypassed while traffic is recorded or replayed, so that every request goes through the cassette.
+func (c *HTTPClient) do(client *http.Client, request *http.Request) (*http.Response, error) {
+
+	send := func(request *http.Request) (*http.Response, error) {
+		return c.send(client, request)
+	}
+
+	mode, _ := GetReplay()
+
+	if cache := GetCache(); cache != nil && c.CacheTTL > 0 && request.Method == "GET" && mode == ReplayOff {
+		return cache.do(request, c.CacheTTL, send)
+	}
+
+	return send(request)
+}
+
+// send sends the request once its host's rate limit allows it, and retries it while it fails with
+// a server error, a 429 or a timeout, according to the configured retries.
+// Only GET and HEAD requests are retried, since repeating other requests, such as logins or uploads, might not be safe.
+func (c *HTTPClient) send(client *http.Client, request *http.Request) (*http.Response, error) {
+
+	retries := GetRetries()
+
+	if request.Method != "GET" && request.Method != "HEAD" {
+		retries.MaxRetries = 0
+	}
+
+	ctx := request.Context()
+	host := request.URL.Host
+	mode, _ := GetReplay()
+
+	if mode == ReplayReplay {
+		// Replayed requests never reach the host, so they are neither rate limited nor retried.
+		return client.Do(request)
+	}
+
+	for retry := 0; ; retry++ {
+
+		attempt := request
+
+		if retry > 0 {
+			attempt = request.Clone(ctx)
+		}
+
+		// The token is taken right before each attempt, after any wait for a retry.
+		if err := limiter.wait(ctx, host); err != nil {
+			return nil, err
+		}
+
+		res, err := client.Do(attempt)
+
+		if retry >= retries.MaxRetries || !shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		delay := retries.delay(retry + 1)
+
+		if after := retryAfter(res); after > 0 {
+
+			if after > retries.GetMaxBackoff() {
+				// Waiting this long is not worth it, so the response is returned as it is.
+				return res, err
+			}
+
+			// The other requests to the host have to wait as well.
+			limiter.block(host, after)
+			delay = after
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+
+		if os.Getenv("GOIRATE_DEBUG") == "true" {
+			log.Printf("retrying %v%v in %v: %v\n", host, request.URL.Path, delay, describeFailure(res, err))
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func describeFailure(res *http.Response, err error) string {
+
+	if err != nil {
+		return err.Error()
+	}
+
+	return res.Status
 }
 
 // Get fetches an HTTP url and returns a goquery.Document.
 // It will also set the appropriate headers to make sure the pages are returned in English.
 func (c *HTTPClient) Get(urlString string) (*goquery.Document, error) {
 
-	client := http.Client{
-		Timeout: c.Timeout,
+	client, err := c.client()
+
+	if err != nil {
+		return nil, err
 	}
 
 	urlData, _ := url.Parse(urlString)
 
-	request, _ := http.NewRequest("GET", urlString, nil)
+	request, err := c.newRequest("GET", urlString, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
 	request.Header.Set("Accept-Language", "en-US,en;q=0.8,gd;q=0.6")
 	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36")
 	request.Header.Set("X-FORWARDED-FOR", "165.234.102.177")
@@ -41,7 +180,7 @@ func (c *HTTPClient) Get(urlString string) (*goquery.Document, error) {
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer: %v", c.AuthToken))
 	}
 
-	res, err := client.Do(request)
+	res, err := c.do(client, request)
 
 	if err != nil {
 
@@ -69,11 +208,18 @@ func (c *HTTPClient) Get(urlString string) (*goquery.Document, error) {
 // given object into JSON.
 func (c *HTTPClient) GetJSON(url string, resp interface{}) error {
 
-	client := http.Client{
-		Timeout: c.Timeout,
+	client, err := c.client()
+
+	if err != nil {
+		return err
+	}
+
+	request, err := c.newRequest("GET", url, nil)
+
+	if err != nil {
+		return err
 	}
 
-	request, _ := http.NewRequest("GET", url, nil)
 	request.Header.Set("Accept-Language", "en-US,en;q=0.8,gd;q=0.6")
 	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36")
 	request.Header.Set("Accept", "application/json")
@@ -83,7 +229,7 @@ func (c *HTTPClient) GetJSON(url string, resp interface{}) error {
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.AuthToken))
 	}
 
-	res, err := client.Do(request)
+	res, err := c.do(client, request)
 
 	if err != nil {
 		return err
@@ -102,12 +248,59 @@ func (c *HTTPClient) GetJSON(url string, resp interface{}) error {
 	return err
 }
 
+// GetXML executes an HTTP get request on the given url and deserializes
+// the XML response into the given object.
+func (c *HTTPClient) GetXML(url string, resp interface{}) error {
+
+	client, err := c.client()
+
+	if err != nil {
+		return err
+	}
+
+	request, err := c.newRequest("GET", url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept-Language", "en-US,en;q=0.8,gd;q=0.6")
+	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36")
+	request.Header.Set("Accept", "application/xml, text/xml")
+	request.Close = true
+
+	res, err := c.do(client, request)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+
+		return fmt.Errorf("http: %v -> %v", url, res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return err
+	}
+
+	err = xml.Unmarshal(body, resp)
+
+	return err
+}
+
 // Post executes an HTTP post request on the given url by serializing the
 // given object into JSON.
 func (c *HTTPClient) Post(url string, req interface{}, resp interface{}) error {
 
-	client := http.Client{
-		Timeout: c.Timeout,
+	client, err := c.client()
+
+	if err != nil {
+		return err
 	}
 
 	jsonBytes, err := json.Marshal(req)
@@ -116,7 +309,12 @@ func (c *HTTPClient) Post(url string, req interface{}, resp interface{}) error {
 		return err
 	}
 
-	request, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonBytes))
+	request, err := c.newRequest("POST", url, bytes.NewBuffer(jsonBytes))
+
+	if err != nil {
+		return err
+	}
+
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 	request.Close = true
@@ -125,7 +323,7 @@ func (c *HTTPClient) Post(url string, req interface{}, resp interface{}) error {
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer: %v", c.AuthToken))
 	}
 
-	res, err := client.Do(request)
+	res, err := c.do(client, request)
 
 	if err != nil {
 		return err
@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestHTTPGet(t *testing.T) {
@@ -55,6 +60,30 @@ func TestHTTPPost(t *testing.T) {
 	HTTPGetJSON("https://jsonplaceholder.typicode.com/posts/102", &obj[1])
 }
 
+func TestHTTPClientContext(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	client := HTTPClient{Context: ctx}
+
+	var resp interface{}
+	err := client.GetJSON(server.URL, &resp)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
 func TestGetFileDocument(t *testing.T) {
 
 	expected := "Cast Away (2000) - IMDbTryIMDbProFree"
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // OptionalBoolean defines a boolean constant that can also be undefined.
@@ -62,6 +65,8 @@ func NormalizeQuery(query string) string {
 		{"!", " "},
 		{"(", " "},
 		{")", " "},
+		{"[", " "},
+		{"]", " "},
 	}
 
 	query = strings.ToLower(query)
@@ -78,3 +83,30 @@ func NormalizeQuery(query string) string {
 
 	return query
 }
+
+// ParseDuration parses a duration such as 90m, 12h, 7d, 2w or 1y.
+// Durations accepted by time.ParseDuration, such as 1h30m, are also accepted.
+func ParseDuration(value string) (time.Duration, error) {
+
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return duration, nil
+	}
+
+	units := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+
+	m := regexp.MustCompile(`^(\d+(?:\.\d+)?)([mhdwy])$`).FindStringSubmatch(strings.ToLower(value))
+
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q, expected a number followed by m, h, d, w or y", value)
+	}
+
+	n, _ := strconv.ParseFloat(m[1], 64)
+
+	return time.Duration(n * float64(units[m[2]])), nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNormalizeQuery(t *testing.T) {
@@ -13,6 +14,7 @@ func TestNormalizeQuery(t *testing.T) {
 		{"The Hitchhiker's Guide to the Galaxy", "the hitchhiker s guide to the galaxy"},
 		{"American Dad!", "american dad"},
 		{"     a     lot    Of!spaces here!  ", "a lot of spaces here"},
+		{"[Erai-raws] One Piece - 1071 [720p][Multiple Subtitle]", "erai raws one piece 1071 720p multiple subtitle"},
 	}
 
 	for _, tt := range table {
@@ -81,3 +83,33 @@ func TestOverridenBy(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	table := []struct {
+		in  string
+		out time.Duration
+		err bool
+	}{
+		{"90m", 90 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2W", 14 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"1y", 365 * 24 * time.Hour, false},
+		{"-1h", 0, true},
+		{"7 days", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(t *testing.T) {
+
+			d, err := ParseDuration(tt.in)
+
+			if (err != nil) != tt.err || d != tt.out {
+				t.Errorf("got %v (%v), want %v", d, err, tt.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// RequestCategory groups the outbound requests of the library, so that each group can be routed through a different proxy.
+type RequestCategory string
+
+const (
+	// TorrentRequests are the requests made to torrent sites, such as the Pirate Bay mirrors and the proxy lists.
+	TorrentRequests RequestCategory = "torrents"
+	// MetadataRequests are the requests made to metadata APIs, such as the TVDB and the OMDb.
+	MetadataRequests RequestCategory = "metadata"
+	// DownloadRequests are the requests made to the torrent client.
+	DownloadRequests RequestCategory = "download"
+)
+
+// ProxyConfig holds the address of a proxy server and the credentials used to authenticate with it.
+// The supported schemes are http://, https:// and socks5://.
+type ProxyConfig struct {
+	URL      string `toml:"url"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+// ProxySettings holds the proxy used by default, along with the proxies used for specific categories of requests.
+// Categories without a proxy of their own fall back to the default one.
+type ProxySettings struct {
+	ProxyConfig
+	Torrents ProxyConfig `toml:"torrents"`
+	Metadata ProxyConfig `toml:"metadata"`
+	Download ProxyConfig `toml:"download"`
+}
+
+var (
+	proxyMutex    sync.RWMutex
+	proxySettings ProxySettings
+	transports    = make(map[string]*http.Transport)
+	baseTransport = http.DefaultTransport.(*http.Transport).Clone()
+)
+
+// ProxyURL returns the URL of the proxy, with the credentials included.
+// If no proxy is configured, it returns nil.
+func (p ProxyConfig) ProxyURL() (*url.URL, error) {
+
+	if p.URL == "" {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme in %v, expected http, https or socks5", p.URL)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy: missing host in %v", p.URL)
+	}
+
+	if p.Username != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	return proxyURL, nil
+}
+
+// For returns the proxy used for the given category of requests.
+func (s ProxySettings) For(category RequestCategory) ProxyConfig {
+
+	var proxy ProxyConfig
+
+	switch category {
+	case TorrentRequests:
+		proxy = s.Torrents
+	case MetadataRequests:
+		proxy = s.Metadata
+	case DownloadRequests:
+		proxy = s.Download
+	}
+
+	if proxy.URL == "" {
+		return s.ProxyConfig
+	}
+
+	return proxy
+}
+
+// Validate returns an error if any of the configured proxies is invalid.
+func (s ProxySettings) Validate() error {
+
+	for _, proxy := range []ProxyConfig{s.ProxyConfig, s.Torrents, s.Metadata, s.Download} {
+		if _, err := proxy.ProxyURL(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetProxies sets the proxies through which the requests of the library are made.
+func SetProxies(settings ProxySettings) error {
+
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+
+	proxySettings = settings
+
+	return nil
+}
+
+// GetProxies returns the proxies through which the requests of the library are made.
+func GetProxies() ProxySettings {
+
+	proxyMutex.RLock()
+	defer proxyMutex.RUnlock()
+
+	return proxySettings
+}
+
+// ProxyTransport returns a transport which goes through the proxy of the given category,
+// and records or replays its traffic if set through SetReplay.
+// It is meant for clients which build their own requests, such as the one of the torrent client.
+func ProxyTransport(category RequestCategory) (http.RoundTripper, error) {
+
+	transport, err := transportFor(category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return replayTransportFor(transport), nil
+}
+
+// transportFor returns the transport used for the given category of requests.
+// Transports are shared between requests going through the same proxy, instead of being created for every request.
+func transportFor(category RequestCategory) (*http.Transport, error) {
+
+	proxyURL, err := GetProxies().For(category).ProxyURL()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL == nil {
+		return baseTransport, nil
+	}
+
+	proxyMutex.Lock()
+	defer proxyMutex.Unlock()
+
+	key := proxyURL.String()
+
+	if transport, exists := transports[key]; exists {
+		return transport, nil
+	}
+
+	transport := baseTransport.Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	transports[key] = transport
+
+	return transport, nil
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerSecond is the rate of requests to each host in the default configuration.
+const DefaultRequestsPerSecond = 2.0
+
+// DefaultRateBurst is the number of requests that can be made to a host at once in the default configuration.
+const DefaultRateBurst = 5
+
+// RateLimitConfig configures how many requests are made to each host, shared between all concurrent requests.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of requests to each host, with zero or less disabling the limit.
+	RequestsPerSecond float64 `toml:"requests_per_second"`
+
+	// Burst is the number of requests that can be made to a host at once, before the rate applies.
+	Burst int `toml:"burst"`
+}
+
+// tokenBucket limits the requests made to a single host.
+type tokenBucket struct {
+	tokens       float64
+	updated      time.Time
+	blockedUntil time.Time
+}
+
+// rateLimiter holds a token bucket for every host that requests have been made to.
+type rateLimiter struct {
+	mutex   sync.Mutex
+	config  RateLimitConfig
+	buckets map[string]*tokenBucket
+}
+
+var limiter = &rateLimiter{buckets: make(map[string]*tokenBucket)}
+
+// SetRateLimit sets the rate at which the library makes requests to each host.
+func SetRateLimit(config RateLimitConfig) {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.config = config
+	limiter.buckets = make(map[string]*tokenBucket)
+}
+
+// GetRateLimit returns the rate at which the library makes requests to each host.
+func GetRateLimit() RateLimitConfig {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	return limiter.config
+}
+
+// reserve takes a token from the bucket of the given host, returning how long to wait before the request can be made.
+// While the host is blocked, no token is taken, and it returns how long the block lasts along with false.
+func (l *rateLimiter) reserve(host string) (time.Duration, bool) {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	bucket, exists := l.buckets[host]
+
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(l.burst()), updated: now}
+		l.buckets[host] = bucket
+	}
+
+	if blocked := bucket.blockedUntil.Sub(now); blocked > 0 {
+		// Tokens are only taken once the block is over, so that the waiting requests are still spread out by the rate.
+		return blocked, false
+	}
+
+	var wait time.Duration
+
+	if l.config.RequestsPerSecond > 0 {
+
+		elapsed := now.Sub(bucket.updated).Seconds()
+		bucket.tokens = math.Min(float64(l.burst()), bucket.tokens+elapsed*l.config.RequestsPerSecond)
+		bucket.updated = now
+
+		// Tokens are taken even when there are none left, so that the requests waiting on the bucket are queued.
+		bucket.tokens--
+
+		if bucket.tokens < 0 {
+			wait = time.Duration(-bucket.tokens / l.config.RequestsPerSecond * float64(time.Second))
+		}
+	}
+
+	return wait, true
+}
+
+// block stops all requests to the given host for the given duration, such as when the host responds with a Retry-After.
+func (l *rateLimiter) block(host string, duration time.Duration) {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	bucket, exists := l.buckets[host]
+
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(l.burst()), updated: time.Now()}
+		l.buckets[host] = bucket
+	}
+
+	if until := time.Now().Add(duration); until.After(bucket.blockedUntil) {
+		bucket.blockedUntil = until
+	}
+}
+
+// wait blocks until a request can be made to the given host, or until the context is cancelled.
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+
+	for {
+
+		wait, reserved := l.reserve(host)
+
+		if err := sleepContext(ctx, wait); err != nil || reserved {
+			return err
+		}
+	}
+}
+
+func (l *rateLimiter) burst() int {
+
+	if l.config.Burst < 1 {
+		return 1
+	}
+
+	return l.config.Burst
+}
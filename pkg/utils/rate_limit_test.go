@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+
+	l := &rateLimiter{
+		config:  RateLimitConfig{RequestsPerSecond: 10, Burst: 2},
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	var tests = []struct {
+		host string
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"a.example.org", 0, 0},
+		{"a.example.org", 0, 0},
+		{"a.example.org", 90 * time.Millisecond, 100 * time.Millisecond},
+		{"a.example.org", 190 * time.Millisecond, 200 * time.Millisecond},
+		{"b.example.org", 0, 0},
+	}
+	for _, tt := range tests {
+
+		got, _ := l.reserve(tt.host)
+
+		if got < tt.min || got > tt.max {
+			t.Errorf("%v: got %v, want between %v and %v", tt.host, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+
+	l := &rateLimiter{
+		config:  RateLimitConfig{RequestsPerSecond: 0.001, Burst: 2},
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	if got, reserved := l.reserve("example.org"); got != 0 || !reserved {
+		t.Errorf("got %v, want no wait for the first request", got)
+	}
+
+	l.block("example.org", time.Second)
+
+	for i := 0; i < 3; i++ {
+
+		if got, reserved := l.reserve("example.org"); got < 900*time.Millisecond || got > time.Second || reserved {
+			t.Errorf("got %v, want the host to be blocked for a second", got)
+		}
+	}
+
+	// No tokens are taken while the host is blocked, so one is still left once it is over.
+	l.buckets["example.org"].blockedUntil = time.Now()
+
+	if got, reserved := l.reserve("example.org"); got != 0 || !reserved {
+		t.Errorf("got %v, want the remaining token to be taken after the block", got)
+	}
+
+	if got, _ := l.reserve("other.example.org"); got != 0 {
+		t.Errorf("got %v, want other hosts to be unaffected", got)
+	}
+}
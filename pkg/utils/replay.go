@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ReplayMode determines whether the HTTP traffic of the library is recorded to, or replayed from, a cassette directory.
+type ReplayMode string
+
+const (
+	// ReplayOff sends requests normally.
+	ReplayOff ReplayMode = ""
+	// ReplayRecord sends requests normally, while storing each response in the cassette.
+	ReplayRecord ReplayMode = "record"
+	// ReplayReplay serves every request from the cassette, without ever reaching the network.
+	ReplayReplay ReplayMode = "replay"
+)
+
+// DefaultCassetteDir is the directory of the cassette, when the GOIRATE_HTTP_CASSETTE variable is not set.
+const DefaultCassetteDir = "cassette"
+
+// redactedParams are the query parameters whose values are not stored in a cassette, since they hold credentials.
+var redactedParams = []string{"apikey", "api_key", "passkey", "token"}
+
+// redactedFields are the fields of JSON responses whose values are not stored in a cassette, such as the token returned by the TVDB login.
+var redactedFields = []string{"apikey", "api_key", "passkey", "token", "userkey"}
+
+// redactedRequestFields are the fields of JSON request bodies which are left out of the names of the recordings,
+// such as the credentials sent to the TVDB login, so that a cassette can be replayed by a different user.
+var redactedRequestFields = append([]string{"username", "password"}, redactedFields...)
+
+// recording is a response stored in a cassette. The body is encoded as base64, so that it is replayed byte for byte.
+// The headers of the request are not stored, since they can hold credentials such as cookies and API keys.
+type recording struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// replayTransport records or replays the requests that go through it.
+type replayTransport struct {
+	mode ReplayMode
+	dir  string
+	next http.RoundTripper
+}
+
+var (
+	replayMutex sync.RWMutex
+	replayMode  ReplayMode
+	cassetteDir string
+)
+
+func init() {
+
+	cassetteDir = os.Getenv("GOIRATE_HTTP_CASSETTE")
+
+	if cassetteDir == "" {
+		cassetteDir = DefaultCassetteDir
+	}
+
+	switch mode := ReplayMode(strings.ToLower(os.Getenv("GOIRATE_HTTP_REPLAY"))); mode {
+	case ReplayRecord, ReplayReplay:
+		replayMode = mode
+	}
+}
+
+// SetReplay sets whether the HTTP traffic of the library is recorded to, or replayed from, the given cassette directory.
+// It overrides the GOIRATE_HTTP_REPLAY and GOIRATE_HTTP_CASSETTE environment variables.
+func SetReplay(mode ReplayMode, dir string) {
+
+	replayMutex.Lock()
+	defer replayMutex.Unlock()
+
+	replayMode = mode
+	cassetteDir = dir
+}
+
+// GetReplay returns whether the HTTP traffic of the library is recorded or replayed, along with the cassette directory.
+func GetReplay() (ReplayMode, string) {
+
+	replayMutex.RLock()
+	defer replayMutex.RUnlock()
+
+	return replayMode, cassetteDir
+}
+
+// replayTransportFor wraps the given transport, if HTTP traffic is being recorded or replayed.
+func replayTransportFor(next http.RoundTripper) http.RoundTripper {
+
+	mode, dir := GetReplay()
+
+	if mode == ReplayOff {
+		return next
+	}
+
+	return &replayTransport{mode: mode, dir: dir, next: next}
+}
+
+func (t *replayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	file, err := t.path(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == ReplayReplay {
+
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return nil, fmt.Errorf("replay: no recorded response for %v %v", request.Method, redactURL(request.URL))
+		}
+
+		var rec recording
+
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("replay: %v: %v", file, err)
+		}
+
+		return rec.response(request), nil
+	}
+
+	res, err := t.next.RoundTrip(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rec := recording{
+		Method: request.Method,
+		URL:    redactURL(request.URL),
+		Status: res.StatusCode,
+		Header: res.Header.Clone(),
+		Body:   redactBody(body, redactedFields),
+	}
+	rec.Header.Del("Set-Cookie")
+
+	if err := rec.save(file); err != nil {
+		return nil, fmt.Errorf("record: %v", err)
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return res, nil
+}
+
+// path returns the file in which the response to the request is recorded. It is derived from the redacted URL
+// and request body, so that requests to the same URL with different bodies are recorded separately,
+// while a cassette can still be replayed with different credentials.
+func (t *replayTransport) path(request *http.Request) (string, error) {
+
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + redactURL(request.URL)))
+
+	if request.GetBody != nil {
+
+		body, err := request.GetBody()
+
+		if err != nil {
+			return "", err
+		}
+
+		data, err := ioutil.ReadAll(body)
+		body.Close()
+
+		if err != nil {
+			return "", err
+		}
+
+		hash.Write([]byte("\n"))
+
+		if strings.HasPrefix(request.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			hash.Write(redactForm(data, redactedRequestFields))
+		} else {
+			hash.Write(redactBody(data, redactedRequestFields))
+		}
+	}
+
+	return filepath.Join(t.dir, hex.EncodeToString(hash.Sum(nil))+".json"), nil
+}
+
+func (rec *recording) save(file string) error {
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+func (rec *recording) response(request *http.Request) *http.Response {
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       request,
+	}
+}
+
+// redactURL returns the URL with the values of the query parameters that hold credentials removed.
+func redactURL(u *url.URL) string {
+
+	redacted := *u
+	redacted.User = nil
+
+	query := redacted.Query()
+
+	for name := range query {
+		for _, param := range redactedParams {
+			if strings.EqualFold(name, param) {
+				query.Set(name, "REDACTED")
+			}
+		}
+	}
+
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+// redactForm returns the form-encoded body with the values of the given fields removed,
+// such as the password sent to the login of the torrent client.
+func redactForm(body []byte, fields []string) []byte {
+
+	form, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		return body
+	}
+
+	for name := range form {
+		for _, field := range fields {
+			if strings.EqualFold(name, field) {
+				form.Set(name, "REDACTED")
+			}
+		}
+	}
+
+	return []byte(form.Encode())
+}
+
+// redactBody returns the body with the values of the given JSON fields removed.
+// Bodies that are not JSON, or have no such fields, are returned unchanged.
+func redactBody(body []byte, fields []string) []byte {
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil || !redactFields(value, fields) {
+		return body
+	}
+
+	redacted, err := json.Marshal(value)
+
+	if err != nil {
+		return body
+	}
+
+	return redacted
+}
+
+// redactFields replaces the values of the given fields in a decoded JSON value, returning whether any were found.
+func redactFields(value interface{}, fields []string) bool {
+
+	redacted := false
+
+	switch value := value.(type) {
+
+	case map[string]interface{}:
+
+		for name, field := range value {
+
+			sensitive := false
+
+			for _, param := range fields {
+				sensitive = sensitive || strings.EqualFold(name, param)
+			}
+
+			if sensitive {
+				value[name] = "REDACTED"
+				redacted = true
+			} else if redactFields(field, fields) {
+				redacted = true
+			}
+		}
+
+	case []interface{}:
+
+		for _, item := range value {
+			if redactFields(item, fields) {
+				redacted = true
+			}
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRetries is the number of times a failed request is retried in the default configuration.
+const DefaultMaxRetries = 2
+
+// DefaultRetryBackoff is the time waited before the first retry of a request, when the configuration does not specify one.
+const DefaultRetryBackoff = time.Second
+
+// DefaultMaxRetryBackoff is the longest time waited before retrying a request, when the configuration does not specify one.
+const DefaultMaxRetryBackoff = 30 * time.Second
+
+// RetryConfig configures how requests that fail with a server error, a 429 or a timeout are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of times a failed request is retried, with zero or less disabling retries.
+	MaxRetries int `toml:"max_retries"`
+
+	// Backoff is the time waited before the first retry, such as 500ms, which doubles with every retry.
+	Backoff string `toml:"backoff"`
+
+	// MaxBackoff is the longest time waited before a retry, such as 30s. Requests whose Retry-After
+	// header asks for a longer wait are not retried.
+	MaxBackoff string `toml:"max_backoff"`
+}
+
+var (
+	retryMutex  sync.RWMutex
+	retryConfig RetryConfig
+)
+
+// SetRetries sets how the failed requests of the library are retried.
+func SetRetries(config RetryConfig) {
+
+	retryMutex.Lock()
+	defer retryMutex.Unlock()
+
+	retryConfig = config
+}
+
+// GetRetries returns how the failed requests of the library are retried.
+func GetRetries() RetryConfig {
+
+	retryMutex.RLock()
+	defer retryMutex.RUnlock()
+
+	return retryConfig
+}
+
+// GetBackoff returns the time waited before the first retry.
+func (r RetryConfig) GetBackoff() time.Duration {
+
+	return parseDurationOr(r.Backoff, DefaultRetryBackoff)
+}
+
+// GetMaxBackoff returns the longest time waited before a retry.
+func (r RetryConfig) GetMaxBackoff() time.Duration {
+
+	return parseDurationOr(r.MaxBackoff, DefaultMaxRetryBackoff)
+}
+
+// delay returns how long to wait before the given retry, starting from 1, with half of the delay being random
+// so that concurrent requests do not retry all at once.
+func (r RetryConfig) delay(retry int) time.Duration {
+
+	delay := r.GetMaxBackoff()
+
+	if retry < 32 {
+
+		if exponential := r.GetBackoff() << uint(retry-1); exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// shouldRetry returns true if a request that completed with the given response or error is worth retrying.
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+
+	if ctx.Err() != nil {
+		// The request was cancelled, rather than timing out on its own.
+		return false
+	}
+
+	if err != nil {
+
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+// retryAfter returns the wait requested by the Retry-After header of the response, which is either
+// a number of seconds or an HTTP date. It returns zero if the response does not request a wait.
+func retryAfter(res *http.Response) time.Duration {
+
+	if res == nil {
+		return 0
+	}
+
+	header := res.Header.Get("Retry-After")
+
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+// sleepContext waits for the given duration, returning early with an error if the context is cancelled.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func parseDurationOr(value string, defaultValue time.Duration) time.Duration {
+
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+
+	return duration
+}
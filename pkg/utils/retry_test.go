@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	var tests = []struct {
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"", 0, 0},
+		{"5", 5 * time.Second, 5 * time.Second},
+		{"0", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+
+			res := &http.Response{Header: http.Header{}}
+			res.Header.Set("Retry-After", tt.header)
+
+			got := retryAfter(res)
+
+			if got < tt.min || got > tt.max {
+				t.Errorf("got %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+
+	retries := RetryConfig{Backoff: "100ms", MaxBackoff: "1s"}
+
+	var tests = []struct {
+		retry int
+		max   time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.max.String(), func(t *testing.T) {
+
+			for i := 0; i < 20; i++ {
+
+				got := retries.delay(tt.retry)
+
+				if got < tt.max/2 || got > tt.max {
+					t.Errorf("got %v, want between %v and %v", got, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetry(t *testing.T) {
+
+	SetRetries(RetryConfig{MaxRetries: 2, Backoff: "1ms", MaxBackoff: "50ms"})
+	defer SetRetries(RetryConfig{})
+
+	var tests = []struct {
+		name     string
+		statuses []int
+		header   string
+		calls    int
+		err      bool
+	}{
+		{"recovers", []int{503, 502, 200}, "", 3, false},
+		{"gives up", []int{500, 500, 500, 200}, "", 3, true},
+		{"client error", []int{404, 200}, "", 1, true},
+		{"short retry after", []int{429, 200}, "0", 2, false},
+		{"long retry after", []int{429, 200}, "3600", 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+				status := tt.statuses[calls]
+				calls++
+
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+
+				w.WriteHeader(status)
+				w.Write([]byte(`<rss></rss>`))
+			}))
+			defer server.Close()
+
+			var client HTTPClient
+			var resp struct{}
+
+			err := client.GetXML(server.URL, &resp)
+
+			if (err != nil) != tt.err {
+				t.Errorf("got error %v, want error %v", err, tt.err)
+			}
+
+			if calls != tt.calls {
+				t.Errorf("got %v calls, want %v", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetryPost(t *testing.T) {
+
+	SetRetries(RetryConfig{MaxRetries: 2, Backoff: "1ms", MaxBackoff: "50ms"})
+	defer SetRetries(RetryConfig{})
+
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var client HTTPClient
+	var resp struct{}
+
+	if err := client.Post(server.URL, map[string]string{"username": "user"}, &resp); err == nil {
+		t.Errorf("expected an error for a failed POST")
+	}
+
+	if calls != 1 {
+		t.Errorf("got %v calls, want the POST to not be retried", calls)
+	}
+}